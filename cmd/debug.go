@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Korpenter/club/internal/debugger"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const debugUsage = "Usage: %s debug [--config <path>] [--extended-time] [--input auto|text|jsonl] <path_to_input_file>"
+
+func debugCmd(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" debug", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a JSON configuration file")
+	extendedTime := fs.Bool("extended-time", false, "read HH:MM:SS or RFC 3339 timestamps and write times with seconds")
+	input := fs.String("input", handler.InputAuto, "event line format: auto, text or jsonl")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), debugUsage+"\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf(debugUsage, os.Args[0])
+	}
+	if !handler.ValidInput(*input) {
+		log.Fatalf("Unknown input format %q", *input)
+	}
+
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", path, err)
+	}
+	d, err := debugger.Load(f, *configPath, *input, utils.TimeFormat{Extended: *extendedTime})
+	f.Close()
+	if err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	if err := d.Run(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
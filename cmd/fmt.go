@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Korpenter/club/internal/normalize"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const fmtUsage = "Usage: %s fmt [--config <path>] [--extended-time] [-w] <path_to_input_file>"
+
+func fmtCmd(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" fmt", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a JSON configuration file")
+	extendedTime := fs.Bool("extended-time", false, "read HH:MM:SS or RFC 3339 timestamps")
+	write := fs.Bool("w", false, "write the result back to the file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), fmtUsage+"\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf(fmtUsage, os.Args[0])
+	}
+
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", path, err)
+	}
+	var buf bytes.Buffer
+	var w io.Writer = os.Stdout
+	if *write {
+		w = &buf
+	}
+	problems, err := normalize.Normalize(w, f, *configPath, utils.TimeFormat{Extended: *extendedTime})
+	f.Close()
+	if err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	if *write {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
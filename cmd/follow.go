@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Korpenter/club/internal/follow"
+	"github.com/Korpenter/club/internal/report"
+)
+
+// followFile processes an event log while it is being appended to. The day
+// ends on a closing marker line or on SIGINT/SIGTERM.
+func followFile(inputFilePath string, opts *runOptions, poll time.Duration) {
+	file, err := os.Open(inputFilePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", inputFilePath, err)
+	}
+	defer file.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := opts.load(bufio.NewReader(follow.NewReader(ctx, file, poll)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cfg.Output.Format != "" && cfg.Output.Format != report.FormatText {
+		log.Fatalf("Follow mode only supports text output")
+	}
+	handler, err := newHandler(cfg, opts, opts.explainOut, opts.receipts)
+	if err != nil {
+		log.Fatalf("Failed to set up receipts: %v", err)
+	}
+	if cfg.Output.Path != "" {
+		out, err := os.Create(cfg.Output.Path)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", cfg.Output.Path, err)
+		}
+		defer out.Close()
+		handler.Out = out
+	}
+
+	handler.StartStream()
+	for cfg.FileScanner.Scan() {
+		line := cfg.FileScanner.Text()
+		if follow.IsClosingMarker(line, cfg.Time) {
+			break
+		}
+		if err := handler.ProcessLine(line); err != nil {
+			fmt.Println(err)
+			exitOnViolation(err)
+			return
+		}
+	}
+	if err := handler.EndDay(); err != nil {
+		fmt.Println(err)
+		exitOnViolation(err)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/generator"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const generateUsage = "Usage: %s generate [--seed <n>] [--tables <n>] [--hours 09:00-21:00] [--rate <n>] [--arrivals <per hour,...>] [--session-mean <duration>] [--session-stddev <duration>] [--wait-prob <p>] [--error-prob <p>] [--out <path>]"
+
+func generateCmd(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" generate", flag.ExitOnError)
+	seed := fs.Int64("seed", 1, "random seed, the same seed reproduces the same file")
+	tables := fs.Int("tables", 5, "number of tables")
+	hours := fs.String("hours", "09:00-21:00", "opening and closing time")
+	rate := fs.Int("rate", 10, "hourly rate")
+	arrivals := fs.String("arrivals", "4", "mean arrivals per hour, comma-separated for each opening hour")
+	sessionMean := fs.Duration("session-mean", 90*time.Minute, "mean session length")
+	sessionStdDev := fs.Duration("session-stddev", 30*time.Minute, "session length standard deviation, 0 for exponential lengths")
+	waitProb := fs.Float64("wait-prob", 0.7, "chance that a client waits when no table is free")
+	errorProb := fs.Float64("error-prob", 0.05, "chance of an error-provoking event per arrival")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), generateUsage+"\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	m := generator.Model{
+		Seed:          *seed,
+		Tables:        *tables,
+		HourlyRate:    *rate,
+		SessionMean:   *sessionMean,
+		SessionStdDev: *sessionStdDev,
+		WaitProb:      *waitProb,
+		ErrorProb:     *errorProb,
+	}
+	opening, closing, ok := strings.Cut(*hours, "-")
+	var err1, err2 error
+	m.OpeningTime, err1 = utils.Parse(opening)
+	m.ClosingTime, err2 = utils.Parse(closing)
+	if !ok || err1 != nil || err2 != nil {
+		log.Fatalf("Invalid opening hours %q", *hours)
+	}
+	for _, v := range strings.Split(*arrivals, ",") {
+		r, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			log.Fatalf("Invalid arrival rate %q", v)
+		}
+		m.ArrivalRates = append(m.ArrivalRates, r)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := generator.Generate(w, m); err != nil {
+		log.Fatalf("Failed to generate: %v", err)
+	}
+}
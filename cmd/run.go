@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/app"
+	"github.com/Korpenter/club/internal/audit"
+	"github.com/Korpenter/club/internal/batch"
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/observer"
+	"github.com/Korpenter/club/internal/receipt"
+	"github.com/Korpenter/club/internal/report"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const runUsage = "Usage: %s [--config <path>] [--format text|json|csv] [--stats] [--clients] [--histogram [--bucket <duration>]] [--html <path>] [--jobs <n>] [--out-dir <dir>] [--follow [--poll <duration>]] [--event-log <path>] [--webhook <url> [--webhook-codes <list>] [--webhook-changes]] [--receipts <dir> [--receipt-format text|json]] [--extended-time] [--input auto|text|jsonl] [--explain <path>] [--strict] <path_to_input_file>..."
+
+type runOptions struct {
+	configPath string
+	format     string
+	stats      bool
+	clients    bool
+	histogram  bool
+	bucket     time.Duration
+	html       string
+
+	eventLog       string
+	webhook        string
+	webhookCodes   string
+	webhookChanges bool
+	observers      []service.Observer
+
+	receipts      string
+	receiptFormat string
+
+	extendedTime bool
+	input        string
+
+	explain    string
+	explainOut io.Writer
+
+	strict bool
+}
+
+func (o *runOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", "", "path to a JSON configuration file")
+	fs.StringVar(&o.format, "format", "", "output format: text, json or csv")
+	fs.BoolVar(&o.stats, "stats", false, "append operational statistics to the report")
+	fs.BoolVar(&o.clients, "clients", false, "append a per-client visit summary to the report")
+	fs.BoolVar(&o.histogram, "histogram", false, "append an occupancy and revenue histogram to the report")
+	fs.DurationVar(&o.bucket, "bucket", 0, "histogram bucket size (default 1h)")
+	fs.StringVar(&o.html, "html", "", "also write an HTML report with a timeline to this path")
+	fs.StringVar(&o.eventLog, "event-log", "", "log every event and state change to this path (- for stderr)")
+	fs.StringVar(&o.webhook, "webhook", "", "post events as JSON to this URL")
+	fs.StringVar(&o.webhookCodes, "webhook-codes", "", "comma-separated event codes to post (default all)")
+	fs.BoolVar(&o.webhookChanges, "webhook-changes", false, "also post table and queue changes")
+	fs.StringVar(&o.receipts, "receipts", "", "write a receipt for every client visit to this directory")
+	fs.StringVar(&o.receiptFormat, "receipt-format", receipt.FormatText, "receipt format: text or json")
+	fs.StringVar(&o.explain, "explain", "", "write every outgoing event with the reason behind it to this path (- for stderr)")
+	fs.StringVar(&o.input, "input", handler.InputAuto, "event line format: auto, text or jsonl")
+	fs.BoolVar(&o.strict, "strict", false, "check the club state after every event and stop at the first inconsistency")
+	fs.BoolVar(&o.extendedTime, "extended-time", false, "read HH:MM:SS or RFC 3339 timestamps and write times with seconds")
+}
+
+// timeFormat is the format of the timestamps in the event logs.
+func (o *runOptions) timeFormat() utils.TimeFormat {
+	return utils.TimeFormat{Extended: o.extendedTime}
+}
+
+// load reads the configuration of an event log and applies command-line
+// overrides on top of it.
+func (o *runOptions) load(r *bufio.Reader) (*config.Config, error) {
+	cfg, err := config.Load(r, o.configPath, o.timeFormat())
+	if err != nil {
+		return nil, err
+	}
+	if o.format != "" {
+		cfg.Output.Format = o.format
+	}
+	if o.stats {
+		cfg.Output.Stats = true
+	}
+	if o.clients {
+		cfg.Output.Clients = true
+	}
+	if o.histogram {
+		cfg.Output.Histogram = true
+	}
+	if o.bucket != 0 {
+		cfg.Output.Bucket = config.Duration(o.bucket)
+	}
+	if o.html != "" {
+		cfg.Output.HTML = o.html
+	}
+	return cfg, nil
+}
+
+func (o *runOptions) validate() {
+	if !report.ValidFormat(o.format) {
+		log.Fatalf("Unknown output format %q", o.format)
+	}
+	if o.bucket < 0 {
+		log.Fatalf("Histogram bucket must be positive")
+	}
+	if !receipt.ValidFormat(o.receiptFormat) {
+		log.Fatalf("Unknown receipt format %q", o.receiptFormat)
+	}
+	if !handler.ValidInput(o.input) {
+		log.Fatalf("Unknown input format %q", o.input)
+	}
+}
+
+// openObservers creates the observers requested on the command line. The
+// returned function closes the event log and the explanations.
+func (o *runOptions) openObservers() func() {
+	closeLog := func() {}
+	switch o.explain {
+	case "":
+	case "-":
+		o.explainOut = os.Stderr
+	default:
+		f, err := os.Create(o.explain)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", o.explain, err)
+		}
+		closeLog = func() { f.Close() }
+		o.explainOut = f
+	}
+	switch o.eventLog {
+	case "":
+	case "-":
+		o.observers = append(o.observers, observer.NewLogger(os.Stderr, o.timeFormat()))
+	default:
+		f, err := os.Create(o.eventLog)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", o.eventLog, err)
+		}
+		closeExplain := closeLog
+		closeLog = func() { closeExplain(); f.Close() }
+		o.observers = append(o.observers, observer.NewLogger(f, o.timeFormat()))
+	}
+	if o.webhook != "" {
+		var codes []int
+		for _, c := range strings.Split(o.webhookCodes, ",") {
+			if c = strings.TrimSpace(c); c == "" {
+				continue
+			}
+			code, err := strconv.Atoi(c)
+			if err != nil {
+				log.Fatalf("Invalid webhook event code %q", c)
+			}
+			codes = append(codes, code)
+		}
+		w := observer.NewWebhook(o.webhook, codes)
+		w.Changes = o.webhookChanges
+		w.Time = o.timeFormat()
+		o.observers = append(o.observers, w)
+	}
+	return closeLog
+}
+
+// newHandler builds the handler of one event log. Explanations are written
+// to explain when it is not nil, receipts into the receipts directory when
+// it is not empty.
+func newHandler(cfg *config.Config, opts *runOptions, explain io.Writer, receipts string) (*handler.FileHandler, error) {
+	repo := storage.NewInMemRepo(cfg)
+	service := service.New(cfg, repo)
+	for _, o := range opts.observers {
+		service.AddObserver(o)
+	}
+	if explain != nil {
+		service.AddObserver(observer.NewExplainer(explain, cfg, repo))
+	}
+	h := handler.NewFileHandler(cfg.FileScanner, service, cfg)
+	h.Input = opts.input
+	if opts.strict {
+		h.Check = audit.New(cfg, repo).Check
+	}
+	if receipts != "" {
+		if err := opts.writeReceipts(h, cfg, receipts); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// writeReceipts makes h write receipts into dir, and remove the receipts
+// of visits a correction has undone. A receipt that cannot be written is
+// logged and does not stop processing.
+func (o *runOptions) writeReceipts(h *handler.FileHandler, cfg *config.Config, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	h.VisitEnded = func(v *models.Visit) {
+		if err := receipt.WriteFile(dir, o.receiptFormat, receipt.New(v, cfg)); err != nil {
+			log.Printf("Failed to write receipt: %v", err)
+		}
+	}
+	h.VisitVoided = func(v *models.Visit) {
+		if err := receipt.RemoveFile(dir, o.receiptFormat, receipt.New(v, cfg)); err != nil {
+			log.Printf("Failed to remove receipt: %v", err)
+		}
+	}
+	return nil
+}
+
+func runCmd(args []string) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	opts := &runOptions{}
+	opts.register(fs)
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of files processed in parallel")
+	outDir := fs.String("out-dir", "results", "directory for per-file results when several files are given")
+	followMode := fs.Bool("follow", false, "keep reading the file as it grows until a closing time line or a signal")
+	poll := fs.Duration("poll", 500*time.Millisecond, "how often to check a followed file for new lines")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), runUsage+"\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatalf(runUsage, os.Args[0])
+	}
+	opts.validate()
+	closeObservers := opts.openObservers()
+	defer closeObservers()
+
+	if *followMode {
+		if fs.NArg() != 1 {
+			log.Fatalf("Follow mode takes exactly one file")
+		}
+		followFile(fs.Arg(0), opts, *poll)
+		return
+	}
+
+	if fs.NArg() == 1 && !strings.ContainsAny(fs.Arg(0), "*?[") {
+		if info, err := os.Stat(fs.Arg(0)); err != nil || !info.IsDir() {
+			runFile(fs.Arg(0), opts)
+			return
+		}
+	}
+
+	paths, err := batch.Expand(fs.Args())
+	if err != nil {
+		log.Fatalf("Failed to list input files: %v", err)
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *outDir, err)
+	}
+	names := batch.OutputNames(paths)
+	results := batch.Run(paths, *jobs, func(path string) *batch.Result {
+		return runBatchFile(path, names[path], *outDir, opts)
+	})
+	batch.WriteSummary(os.Stdout, results)
+	for _, r := range results {
+		exitOnViolation(r.Err)
+	}
+}
+
+func runFile(inputFilePath string, opts *runOptions) {
+	file, err := os.Open(inputFilePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", inputFilePath, err)
+	}
+	defer file.Close()
+
+	cfg, err := opts.load(bufio.NewReader(file))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if !report.ValidFormat(cfg.Output.Format) {
+		log.Fatalf("Unknown output format %q", cfg.Output.Format)
+	}
+	handler, err := newHandler(cfg, opts, opts.explainOut, opts.receipts)
+	if err != nil {
+		log.Fatalf("Failed to set up receipts: %v", err)
+	}
+	if cfg.Output.Path != "" {
+		out, err := os.Create(cfg.Output.Path)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", cfg.Output.Path, err)
+		}
+		defer out.Close()
+		handler.Out = out
+	}
+	app := app.NewApp(handler)
+	if err := app.Run(); err != nil {
+		fmt.Println(err)
+		exitOnViolation(err)
+	}
+}
+
+// exitOnViolation ends the process with status 1 when err is a broken
+// invariant found in strict mode.
+func exitOnViolation(err error) {
+	var v *audit.Violation
+	if errors.As(err, &v) {
+		os.Exit(1)
+	}
+}
+
+// runBatchFile writes the result of one file into outDir, in files called
+// name. Format errors are written to the result file just like the
+// single-file mode prints them.
+func runBatchFile(path, name, outDir string, opts *runOptions) *batch.Result {
+	res := &batch.Result{Path: path}
+	file, err := os.Open(path)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer file.Close()
+
+	cfg, err := opts.load(bufio.NewReader(file))
+	if err == nil && !report.ValidFormat(cfg.Output.Format) {
+		err = fmt.Errorf("unknown output format %q", cfg.Output.Format)
+	}
+	var h *handler.FileHandler
+	if err == nil {
+		// Explanations of several files would interleave, so every file
+		// gets its own next to its result.
+		var explain io.Writer
+		if opts.explain != "" {
+			f, createErr := os.Create(filepath.Join(outDir, name+".explain"))
+			if createErr != nil {
+				res.Err = createErr
+				return res
+			}
+			defer f.Close()
+			explain = f
+		}
+		var receipts string
+		if opts.receipts != "" {
+			receipts = filepath.Join(opts.receipts, name)
+		}
+		var setupErr error
+		if h, setupErr = newHandler(cfg, opts, explain, receipts); setupErr != nil {
+			res.Err = setupErr
+			return res
+		}
+		err = h.ProcessEvents()
+	}
+
+	ext := ".out"
+	if err == nil && cfg.Output.Format != "" {
+		ext = "." + cfg.Output.Format
+	}
+	res.Output = filepath.Join(outDir, name+ext)
+	out, createErr := os.Create(res.Output)
+	if createErr != nil {
+		res.Err = createErr
+		return res
+	}
+	defer out.Close()
+	if err != nil {
+		fmt.Fprintln(out, err)
+		res.Err = err
+		return res
+	}
+
+	day := h.Report()
+	if err := h.ClosingErr(); err != nil {
+		fmt.Fprintln(out, err)
+		res.Err = err
+		return res
+	}
+	if err := report.Write(out, cfg.Output.Format, day); err != nil {
+		res.Err = err
+		return res
+	}
+	if cfg.Output.HTML != "" {
+		res.Err = writeHTML(filepath.Join(outDir, name+".html"), day)
+	}
+	res.Events = len(day.Events)
+	for _, p := range day.Profits {
+		res.Revenue += p.Sum
+		res.Occupied += p.Table.TotalTime
+	}
+	return res
+}
+
+func writeHTML(path string, day *report.Day) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.WriteHTML(f, day)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/simulate"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const simulateUsage = "Usage: %s simulate [--config <path>] [--tables <n>] [--queue <n>] [--rate <amount>] [--remap missing|free] [--extended-time] <path_to_input_file>"
+
+func simulateCmd(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" simulate", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a JSON configuration file")
+	tables := fs.Int("tables", 0, "number of tables to simulate")
+	queue := fs.Int("queue", 0, "queue capacity to simulate (default: number of tables)")
+	rate := fs.String("rate", "", "hourly rate to simulate, such as 149.50")
+	extendedTime := fs.Bool("extended-time", false, "read HH:MM:SS or RFC 3339 timestamps")
+	remap := fs.String("remap", simulate.RemapMissing, "seat remapping: missing tables only, or any busy table too")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), simulateUsage+"\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatalf(simulateUsage, os.Args[0])
+	}
+	if *tables < 0 || *queue < 0 {
+		log.Fatalf("Simulated tables and queue capacity must be positive")
+	}
+	var hourlyRate models.Money
+	if *rate != "" {
+		var err error
+		if hourlyRate, err = models.ParseMoney(*rate); err != nil {
+			log.Fatalf("Invalid simulated rate %q: %v", *rate, err)
+		}
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", fs.Arg(0), err)
+	}
+	cmp, err := simulate.Run(data, *configPath, simulate.Variant{
+		Tables:        *tables,
+		QueueCapacity: *queue,
+		HourlyRate:    hourlyRate,
+		Remap:         *remap,
+	}, utils.TimeFormat{Extended: *extendedTime})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cmp.WriteText(os.Stdout)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// State gives the auditor a copy of the club state.
+type State interface {
+	Snapshot() *storage.Snapshot
+}
+
+// Violation is a broken invariant, with the event after which it was found
+// and the club state at that moment, written with times in the format Time.
+type Violation struct {
+	Event *models.Event
+	Msg   string
+	State *storage.Snapshot
+	Time  utils.TimeFormat
+}
+
+func (v *Violation) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invariant violated after %s: %s\n", v.Event.Format(v.Time), v.Msg)
+	v.State.Write(&b, v.Time)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Auditor checks the invariants of the club after every event:
+//   - a client sits at no more than one table,
+//   - a client waiting in the queue is not seated,
+//   - every seated or waiting client is in the club,
+//   - the total time of a table never decreases,
+//   - the queue is not longer than its capacity.
+type Auditor struct {
+	cfg   *config.Config
+	state State
+	total map[int]time.Duration
+}
+
+func New(cfg *config.Config, state State) *Auditor {
+	return &Auditor{cfg: cfg, state: state, total: make(map[int]time.Duration)}
+}
+
+// Check returns a *Violation for the first broken invariant after e. A
+// correction recomputes the day, so table totals are compared from it on.
+func (a *Auditor) Check(e *models.Event) error {
+	s := a.state.Snapshot()
+	if msg := a.check(e, s); msg != "" {
+		return &Violation{Event: e, Msg: msg, State: s, Time: a.cfg.Time}
+	}
+	return nil
+}
+
+func (a *Auditor) check(e *models.Event, s *storage.Snapshot) string {
+	inClub := make(map[string]bool, len(s.Clients))
+	for _, name := range s.Clients {
+		inClub[name] = true
+	}
+	seated := make(map[string]int)
+	for _, t := range s.Tables {
+		if t.Client == nil {
+			continue
+		}
+		name := t.Client.Name
+		if other, ok := seated[name]; ok {
+			return fmt.Sprintf("%s sits at tables %d and %d", name, other, t.Id)
+		}
+		seated[name] = t.Id
+		if !inClub[name] {
+			return fmt.Sprintf("%s sits at table %d but is not in the club", name, t.Id)
+		}
+	}
+	for _, name := range s.Queue {
+		if id, ok := seated[name]; ok {
+			return fmt.Sprintf("%s waits in the queue while sitting at table %d", name, id)
+		}
+		if !inClub[name] {
+			return fmt.Sprintf("%s waits in the queue but is not in the club", name)
+		}
+	}
+	if len(s.Queue) > a.cfg.QueueCapacity {
+		return fmt.Sprintf("the queue holds %d clients, its capacity is %d", len(s.Queue), a.cfg.QueueCapacity)
+	}
+
+	if e.Code == models.EventCorrection {
+		clear(a.total)
+	}
+	for _, t := range s.Tables {
+		if last, ok := a.total[t.Id]; ok && t.TotalTime < last {
+			return fmt.Sprintf("the total time of table %d went down from %s to %s",
+				t.Id, a.cfg.Time.FormatDuration(last), a.cfg.Time.FormatDuration(t.TotalTime))
+		}
+		a.total[t.Id] = t.TotalTime
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+type fixed struct{ s *storage.Snapshot }
+
+func (f *fixed) Snapshot() *storage.Snapshot { return f.s }
+
+func at(h, m int) time.Time {
+	return time.Date(0, 1, 1, h, m, 0, 0, time.UTC)
+}
+
+func seat(id int, name string) *models.Table {
+	t := &models.Table{Id: id}
+	if name != "" {
+		t.Client = &models.Client{Name: name}
+	}
+	return t
+}
+
+func TestCheck(t *testing.T) {
+	cfg := &config.Config{QueueCapacity: 1}
+	event := &models.Event{Code: models.ClientArrived, Timestamp: at(10, 0), ClientName: "alice"}
+	tests := []struct {
+		name  string
+		state *storage.Snapshot
+		want  string
+	}{
+		{
+			name:  "consistent",
+			state: &storage.Snapshot{Tables: []*models.Table{seat(1, "alice"), seat(2, "")}, Queue: []string{"bob"}, Clients: []string{"alice", "bob"}},
+		},
+		{
+			name:  "two tables",
+			state: &storage.Snapshot{Tables: []*models.Table{seat(1, "alice"), seat(2, "alice")}, Clients: []string{"alice"}},
+			want:  "alice sits at tables 1 and 2",
+		},
+		{
+			name:  "seated and queued",
+			state: &storage.Snapshot{Tables: []*models.Table{seat(1, "alice")}, Queue: []string{"alice"}, Clients: []string{"alice"}},
+			want:  "alice waits in the queue while sitting at table 1",
+		},
+		{
+			name:  "seated but gone",
+			state: &storage.Snapshot{Tables: []*models.Table{seat(1, "alice")}},
+			want:  "alice sits at table 1 but is not in the club",
+		},
+		{
+			name:  "queue over capacity",
+			state: &storage.Snapshot{Tables: []*models.Table{seat(1, "")}, Queue: []string{"alice", "bob"}, Clients: []string{"alice", "bob"}},
+			want:  "the queue holds 2 clients, its capacity is 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(cfg, &fixed{tt.state}).Check(event)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected violation: %v", err)
+				}
+				return
+			}
+			var v *Violation
+			if !errors.As(err, &v) || v.Msg != tt.want {
+				t.Fatalf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalTime(t *testing.T) {
+	cfg := &config.Config{QueueCapacity: 1}
+	state := &fixed{&storage.Snapshot{Tables: []*models.Table{{Id: 1, TotalTime: 2 * time.Hour}}}}
+	a := New(cfg, state)
+	if err := a.Check(&models.Event{Code: models.ClientLeft}); err != nil {
+		t.Fatal(err)
+	}
+	state.s = &storage.Snapshot{Tables: []*models.Table{{Id: 1, TotalTime: time.Hour}}}
+	if err := a.Check(&models.Event{Code: models.EventCorrection}); err != nil {
+		t.Fatalf("a correction recomputes the day: %v", err)
+	}
+	state.s = &storage.Snapshot{Tables: []*models.Table{{Id: 1, TotalTime: 30 * time.Minute}}}
+	err := a.Check(&models.Event{Code: models.ClientLeft})
+	if err == nil || !strings.Contains(err.Error(), "the total time of table 1 went down from 01:00 to 00:30") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+// TestStrictRun moves a client to a busy table while another one waits,
+// which used to free the table without seating anyone from the queue.
+func TestStrictRun(t *testing.T) {
+	log := `2
+09:00 19:00
+10
+09:10 1 alice
+09:11 2 alice 1
+09:12 1 carol
+09:13 2 carol 2
+09:14 1 bob
+09:15 3 bob
+09:20 2 alice 2
+09:25 2 bob 1
+09:30 4 carol
+`
+	cfg, err := config.Load(bufio.NewReader(strings.NewReader(log)), "", utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := storage.NewInMemRepo(cfg)
+	h := handler.NewFileHandler(cfg.FileScanner, service.New(cfg, repo), cfg)
+	h.Check = New(cfg, repo).Check
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("unexpected violation:\n%v", err)
+	}
+}
+
+func TestViolationReport(t *testing.T) {
+	state := &storage.Snapshot{
+		Tables:  []*models.Table{{Id: 1, Client: &models.Client{Name: "bob"}, ClientSat: at(9, 25)}, seat(2, "")},
+		Queue:   []string{"bob"},
+		Clients: []string{"alice", "bob"},
+	}
+	event := &models.Event{Code: models.ClientSat, Timestamp: at(9, 25), ClientName: "bob", TableID: 1}
+	err := New(&config.Config{QueueCapacity: 2}, &fixed{state}).Check(event)
+	want := `invariant violated after 09:25 2 bob 1: bob waits in the queue while sitting at table 1
+tables:
+  1 bob since 09:25
+  2 free
+queue: bob
+clients: alice, bob`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got:\n%v\nwant:\n%s", err, want)
+	}
+}
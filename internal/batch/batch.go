@@ -0,0 +1,179 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+// Expand resolves arguments into input files. Directories contribute their
+// regular, non-hidden files; anything with glob metacharacters is matched
+// with filepath.Glob. Paths to the same file are dropped after the first,
+// order follows the arguments.
+func Expand(args []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if key := absPath(p); !seen[key] {
+			seen[key] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: no files match", arg)
+			}
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(m)
+				continue
+			}
+			entries, err := os.ReadDir(m)
+			if err != nil {
+				return nil, err
+			}
+			var files []string
+			for _, e := range entries {
+				if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+					files = append(files, filepath.Join(m, e.Name()))
+				}
+			}
+			sort.Strings(files)
+			for _, f := range files {
+				add(f)
+			}
+		}
+	}
+	return paths, nil
+}
+
+type Result struct {
+	Path     string
+	Output   string
+	Events   int
+	Revenue  models.Money
+	Occupied time.Duration
+	Err      error
+}
+
+// Run processes paths with at most workers running at once. Results keep
+// the order of paths; a failing file does not affect the others.
+func Run(paths []string, workers int, process func(path string) *Result) []*Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]*Result, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runOne(paths[i], process)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// runOne processes one file. A panic fails that file only.
+func runOne(path string, process func(path string) *Result) (r *Result) {
+	defer func() {
+		if p := recover(); p != nil {
+			r = &Result{Path: path, Err: fmt.Errorf("panic: %v", p)}
+		}
+	}()
+	return process(path)
+}
+
+// OutputNames names the results of paths, without an extension. A name is
+// derived from the path, such as data_valid for ./data/valid.txt. Paths that
+// would get the same name get a short hash of their absolute path appended,
+// so every path has its own name.
+func OutputNames(paths []string) map[string]string {
+	names := make(map[string]string, len(paths))
+	count := make(map[string]int, len(paths))
+	for _, p := range paths {
+		name := filepath.ToSlash(filepath.Clean(p))
+		name = strings.TrimLeft(name, "./")
+		name = strings.ReplaceAll(name, "/", "_")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		names[p] = name
+		count[name]++
+	}
+	for _, p := range paths {
+		if count[names[p]] > 1 {
+			sum := sha256.Sum256([]byte(absPath(p)))
+			names[p] += "-" + hex.EncodeToString(sum[:4])
+		}
+	}
+	return names
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+func WriteSummary(w io.Writer, results []*Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "file\tstatus\tevents\trevenue\toccupied")
+	var ok int
+	var revenue models.Money
+	var occupied time.Duration
+	var failed []*Result
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+			fmt.Fprintf(tw, "%s\tfailed\n", r.Path)
+			continue
+		}
+		ok++
+		revenue += r.Revenue
+		occupied += r.Occupied
+		fmt.Fprintf(tw, "%s\tok\t%d\t%s\t%s\n", r.Path, r.Events, r.Revenue, hours(r.Occupied))
+	}
+	tw.Flush()
+	if len(failed) > 0 {
+		fmt.Fprintln(w, "Failures:")
+		for _, r := range failed {
+			fmt.Fprintf(w, "%s: %v\n", r.Path, r.Err)
+		}
+	}
+	fmt.Fprintf(w, "Total: %d files, %d ok, %d failed, revenue %s, occupied %s\n",
+		len(results), ok, len(failed), revenue, hours(occupied))
+}
+
+func hours(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
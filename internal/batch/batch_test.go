@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", ".hidden", "c.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := Expand([]string{filepath.Join(dir, "c.log"), dir, filepath.Join(dir, "*.txt"), dir + "/sub/../c.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(dir, "c.log"),
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, "b.txt"),
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	if _, err := Expand([]string{filepath.Join(dir, "*.csv")}); err == nil {
+		t.Errorf("Expected error for a glob without matches")
+	}
+}
+
+func TestRun(t *testing.T) {
+	paths := []string{"a", "broken", "c", "d", "e"}
+	var running, peak int32
+	results := Run(paths, 2, func(path string) *Result {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if path == "broken" {
+			return &Result{Path: path, Err: errors.New("10:00 x")}
+		}
+		return &Result{Path: path, Revenue: 10}
+	})
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 files processed at once, got %d", peak)
+	}
+	for i, r := range results {
+		if r.Path != paths[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, paths[i], r.Path)
+		}
+		if (r.Err != nil) != (r.Path == "broken") {
+			t.Errorf("Unexpected error for %s: %v", r.Path, r.Err)
+		}
+	}
+}
+
+func TestOutputNames(t *testing.T) {
+	names := OutputNames([]string{"./data/valid.txt", "a/b.txt", "a_b.txt", "x.txt", "x.log", "../a/b.txt"})
+	if names["./data/valid.txt"] != "data_valid" {
+		t.Errorf("Expected data_valid, got %s", names["./data/valid.txt"])
+	}
+	seen := make(map[string]string)
+	for path, name := range names {
+		if other, ok := seen[name]; ok {
+			t.Errorf("%s and %s both named %s", path, other, name)
+		}
+		seen[name] = path
+	}
+	if !strings.HasPrefix(names["a/b.txt"], "a_b-") || !strings.HasPrefix(names["x.log"], "x-") {
+		t.Errorf("Expected colliding names to keep their base, got %v", names)
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	results := Run([]string{"a", "panics", "c"}, 2, func(path string) *Result {
+		if path == "panics" {
+			panic("broken file")
+		}
+		return &Result{Path: path}
+	})
+	for _, r := range results {
+		if (r.Err != nil) != (r.Path == "panics") {
+			t.Errorf("Unexpected error for %s: %v", r.Path, r.Err)
+		}
+	}
+}
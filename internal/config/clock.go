@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// placeHours moves the opening and closing times into the club's time zone.
+// Without a configured date the day is taken from a dated opening time.
+func (c *Config) placeHours() error {
+	if c.Location == nil {
+		return nil
+	}
+	if c.Date.IsZero() {
+		if !utils.HasDate(c.OpeningTime) {
+			return errors.New("config: a time zone needs a date for times without one")
+		}
+		c.Date = c.OpeningTime.In(c.Location)
+	}
+	c.OpeningTime = c.Place(c.OpeningTime, time.Time{})
+	c.ClosingTime = c.Place(c.ClosingTime, c.OpeningTime)
+	return nil
+}
+
+// Place puts a parsed timestamp into the club's time zone. A time of day
+// falls on the configured date. When the clocks go back and the wall time
+// occurs twice, the first occurrence not before after is taken. A wall time
+// skipped when the clocks go forward is moved forward by the gap.
+func (c *Config) Place(t, after time.Time) time.Time {
+	if c.Location == nil {
+		return t
+	}
+	if utils.HasDate(t) {
+		return t.In(c.Location)
+	}
+	y, m, d := c.Date.Date()
+	wall := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	placed := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), c.Location)
+
+	// The offsets around a transition give both readings of the wall time.
+	_, before := placed.Add(-12 * time.Hour).Zone()
+	_, later := placed.Add(12 * time.Hour).Zone()
+	if before == later {
+		return placed
+	}
+	var candidates []time.Time
+	for _, offset := range []int{before, later} {
+		at := wall.Add(-time.Duration(offset) * time.Second).In(c.Location)
+		if at.Hour() == t.Hour() && at.Minute() == t.Minute() && at.Second() == t.Second() {
+			candidates = append(candidates, at)
+		}
+	}
+	if len(candidates) == 0 {
+		return placed
+	}
+	for _, at := range candidates {
+		if !at.Before(after) {
+			return at
+		}
+	}
+	return candidates[0]
+}
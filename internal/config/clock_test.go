@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func TestPlace(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name  string
+		date  string
+		wall  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "ordinary day", date: "2026-07-01", wall: "10:00", want: at("2026-07-01T10:00:00+02:00")},
+		{name: "spring forward before", date: "2026-03-29", wall: "01:30", want: at("2026-03-29T01:30:00+01:00")},
+		{name: "spring forward after", date: "2026-03-29", wall: "03:30", want: at("2026-03-29T03:30:00+02:00")},
+		{name: "spring forward gap", date: "2026-03-29", wall: "02:30", want: at("2026-03-29T03:30:00+02:00")},
+		{name: "fall back first", date: "2026-10-25", wall: "02:30", want: at("2026-10-25T02:30:00+02:00")},
+		{name: "fall back second", date: "2026-10-25", wall: "02:10",
+			after: at("2026-10-25T02:40:00+02:00"), want: at("2026-10-25T02:10:00+01:00")},
+		{name: "fall back after", date: "2026-10-25", wall: "03:30", want: at("2026-10-25T03:30:00+01:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, _ := time.Parse(time.DateOnly, tt.date)
+			cfg := &Config{Location: berlin, Date: date}
+			wall, err := utils.Parse(tt.wall)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Place(wall, tt.after); !got.Equal(tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
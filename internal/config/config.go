@@ -3,10 +3,13 @@ package config
 import (
 	"bufio"
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Korpenter/club/internal/models"
 	"github.com/Korpenter/club/internal/utils"
 )
 
@@ -14,17 +17,34 @@ type Config struct {
 	NumberOfTables int
 	OpeningTime    time.Time
 	ClosingTime    time.Time
-	HourlyRate     int
+	HourlyRate     models.Money
+	Currency       string
+	Tax            models.Tax
+	QueueCapacity  int
+	MaxPause       time.Duration
+	Discount       Discount
+	Zones          []Zone
+	Output         Output
+
+	// Location is the time zone of the club, nil for the legacy times of
+	// day without one. Date is the day that times of day fall on.
+	Location *time.Location
+	Date     time.Time
+
+	// Time is the format of the timestamps in the event log and in every
+	// output written for it.
+	Time utils.TimeFormat
 
 	FileScanner *bufio.Scanner
 }
 
-func NewConfig(scanner *bufio.Scanner) (*Config, error) {
+func NewConfig(scanner *bufio.Scanner, tf utils.TimeFormat) (*Config, error) {
 	cfg := &Config{
+		Time:        tf,
 		FileScanner: scanner,
 	}
 
-	if cfg.FileScanner.Scan() {
+	if scan(cfg.FileScanner) {
 		line := cfg.FileScanner.Text()
 
 		num, err := strconv.Atoi(line)
@@ -34,34 +54,164 @@ func NewConfig(scanner *bufio.Scanner) (*Config, error) {
 		cfg.NumberOfTables = num
 	}
 
-	if cfg.FileScanner.Scan() {
+	if scan(cfg.FileScanner) {
 		line := cfg.FileScanner.Text()
 		times := strings.Split(line, " ")
 		if len(times) != 2 {
 			return nil, errors.New(line)
 		}
 
-		opening, err := utils.Parse(times[0])
+		opening, err := tf.Parse(times[0])
 		if err != nil {
 			return nil, errors.New(line)
 		}
 		cfg.OpeningTime = opening
 
-		closing, err := utils.Parse(times[1])
+		closing, err := tf.Parse(times[1])
 		if err != nil || !closing.After(opening) {
 			return nil, errors.New(line)
 		}
 		cfg.ClosingTime = closing
 	}
 
-	if cfg.FileScanner.Scan() {
+	if scan(cfg.FileScanner) {
 		line := cfg.FileScanner.Text()
 
-		rate, err := strconv.Atoi(line)
-		if err != nil || rate < 1 {
+		rate, err := models.ParseMoney(line)
+		if err != nil || rate <= 0 {
 			return nil, errors.New(line)
 		}
 		cfg.HourlyRate = rate
 	}
+	cfg.QueueCapacity = cfg.NumberOfTables
+	return cfg, nil
+}
+
+// Load builds the configuration for an event log read through r. Without a
+// config file the legacy three-line header is required. With one the header
+// becomes optional, and values set in the config file take precedence over
+// the header. Timestamps are read in the format tf.
+func Load(r *bufio.Reader, path string, tf utils.TimeFormat) (*Config, error) {
+	if path == "" {
+		return NewConfig(bufio.NewScanner(r), tf)
+	}
+	file, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Time: tf}
+	if HasHeader(r) {
+		cfg, err = NewConfig(bufio.NewScanner(r), tf)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cfg.FileScanner = bufio.NewScanner(r)
+	}
+	if err := file.Apply(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.placeHours(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// HasHeader reports whether the event log starts with the legacy header.
+// Event lines always start with a time, the header starts with the number
+// of tables. Only the first line after any comments is read, so r may still
+// be growing.
+func HasHeader(r *bufio.Reader) bool {
+	var line []byte
+	start := 0
+	for n := 1; n <= r.Size(); n++ {
+		buf, err := r.Peek(n)
+		line = buf[start:]
+		if err != nil {
+			break
+		}
+		if buf[n-1] == '\n' {
+			if !IsComment(string(line)) {
+				break
+			}
+			start = n
+		}
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(line)), " ")
+	return first != "" && !strings.Contains(first, ":")
+}
+
+// IsComment reports whether a line of an event log is a # comment.
+func IsComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// scan advances s to the next line that is not a comment.
+func scan(s *bufio.Scanner) bool {
+	for s.Scan() {
+		if !IsComment(s.Text()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) Validate() error {
+	if c.NumberOfTables < 1 {
+		return errors.New("config: number of tables is not set")
+	}
+	if c.OpeningTime.IsZero() && c.ClosingTime.IsZero() {
+		return errors.New("config: opening hours are not set")
+	}
+	if utils.HasDate(c.OpeningTime) != utils.HasDate(c.ClosingTime) {
+		return errors.New("config: opening and closing times must both have a date or both not")
+	}
+	if !c.ClosingTime.After(c.OpeningTime) {
+		return errors.New("config: closing time must be after opening time")
+	}
+	if c.HourlyRate < 1 {
+		return errors.New("config: hourly rate is not set")
+	}
+	if c.Output.Bucket < 0 {
+		return errors.New("config: histogram bucket must be positive")
+	}
+	if c.QueueCapacity < 0 {
+		return errors.New("config: queue capacity must not be negative")
+	}
+	if c.Discount.Percent < 0 || c.Discount.Percent > 100 || c.Discount.MinHours < 0 {
+		return errors.New("config: discount must be between 0 and 100 percent")
+	}
+	if c.MaxPause < 0 {
+		return errors.New("config: maximum pause must not be negative")
+	}
+	seen := make(map[int]string)
+	for _, z := range c.Zones {
+		if z.HourlyRate < 0 {
+			return fmt.Errorf("config: zone %q: hourly rate must not be negative", z.Name)
+		}
+		for _, id := range z.Tables {
+			if id < 1 || id > c.NumberOfTables {
+				return fmt.Errorf("config: zone %q: table %d does not exist", z.Name, id)
+			}
+			if other, ok := seen[id]; ok {
+				return fmt.Errorf("config: table %d is in zones %q and %q", id, other, z.Name)
+			}
+			seen[id] = z.Name
+		}
+	}
+	return nil
+}
+
+// RateFor returns the hourly rate of a table, taking zone pricing into account.
+func (c *Config) RateFor(tableID int) models.Money {
+	for _, z := range c.Zones {
+		if z.HourlyRate > 0 && slices.Contains(z.Tables, tableID) {
+			return z.HourlyRate
+		}
+	}
+	return c.HourlyRate
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/Korpenter/club/internal/utils"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -22,9 +24,36 @@ func TestNewConfig(t *testing.T) {
 				NumberOfTables: 5,
 				OpeningTime:    time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC),
 				ClosingTime:    time.Date(0, 1, 1, 16, 0, 0, 0, time.UTC),
-				HourlyRate:     10,
+				HourlyRate:     1000,
+			},
+		},
+		{
+			name:        "decimal hourly rate",
+			input:       "5\n08:00 16:00\n149.50\n",
+			expectedErr: "",
+			expectedCfg: &Config{
+				NumberOfTables: 5,
+				OpeningTime:    time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC),
+				ClosingTime:    time.Date(0, 1, 1, 16, 0, 0, 0, time.UTC),
+				HourlyRate:     14950,
 			},
 		},
+		{
+			name:        "comments in the header",
+			input:       "# club one\n5\n# summer hours\n08:00 16:00\n10\n",
+			expectedErr: "",
+			expectedCfg: &Config{
+				NumberOfTables: 5,
+				OpeningTime:    time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC),
+				ClosingTime:    time.Date(0, 1, 1, 16, 0, 0, 0, time.UTC),
+				HourlyRate:     1000,
+			},
+		},
+		{
+			name:        "hourly rate with too many decimals",
+			input:       "3\n08:00 16:00\n10.505\n",
+			expectedErr: "10.505",
+		},
 		{
 			name:        "invalid number of tables",
 			input:       "-3\n08:00 16:00\n10\n",
@@ -60,7 +89,7 @@ func TestNewConfig(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			scanner := bufio.NewScanner(strings.NewReader(tt.input))
-			cfg, err := NewConfig(scanner)
+			cfg, err := NewConfig(scanner, utils.TimeFormat{})
 
 			if tt.expectedErr != "" {
 				if err == nil {
@@ -82,3 +111,12 @@ func TestNewConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestHasHeaderComments(t *testing.T) {
+	if !HasHeader(bufio.NewReader(strings.NewReader("# note\n# more\n3\n09:00 19:00\n10\n"))) {
+		t.Error("expected a header after comments")
+	}
+	if HasHeader(bufio.NewReader(strings.NewReader("# note\n09:10 1 alice\n"))) {
+		t.Error("expected no header before an event")
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+// File is the structured configuration passed with --config. Every section
+// is optional; unset values fall back to the event log header.
+type File struct {
+	Tables  *int         `json:"tables"`
+	Hours   *Hours       `json:"hours"`
+	Pricing *Pricing     `json:"pricing"`
+	Zones   []Zone       `json:"zones"`
+	Queue   *QueuePolicy `json:"queue"`
+	Pause   *PausePolicy `json:"pause"`
+	Output  *Output      `json:"output"`
+
+	// TimeZone is an IANA time zone name such as "Europe/Berlin". Times
+	// of day in the log then fall on Date in that zone.
+	TimeZone string `json:"timezone"`
+	Date     string `json:"date"`
+}
+
+type Hours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+type Pricing struct {
+	HourlyRate *models.Money `json:"hourly_rate"`
+	Discount   *Discount     `json:"discount"`
+	Currency   string        `json:"currency"`
+	Tax        *TaxPolicy    `json:"tax"`
+}
+
+// TaxPolicy is the sales tax, for example {"rate": "20", "included": true,
+// "rounding": "half_up"}. Rounding is one of half_up (the default),
+// half_even, down and up.
+type TaxPolicy struct {
+	Rate     models.Percent `json:"rate"`
+	Included bool           `json:"included"`
+	Rounding string         `json:"rounding"`
+}
+
+// Discount takes Percent off the table time of a visit billed for at least
+// MinHours.
+type Discount struct {
+	Percent  int `json:"percent"`
+	MinHours int `json:"min_hours"`
+}
+
+// Amount is the discount on one table charge of a visit billed for hours in
+// total. It is rounded down.
+func (d Discount) Amount(charge models.Money, hours int) models.Money {
+	if d.Percent <= 0 || hours <= 0 || hours < d.MinHours {
+		return 0
+	}
+	return charge * models.Money(d.Percent) / 100
+}
+
+type Zone struct {
+	Name       string       `json:"name"`
+	Tables     []int        `json:"tables"`
+	HourlyRate models.Money `json:"hourly_rate"`
+}
+
+type QueuePolicy struct {
+	Capacity *int `json:"capacity"`
+}
+
+// PausePolicy limits how long a paused session keeps its table. Zero means
+// no limit.
+type PausePolicy struct {
+	Max Duration `json:"max"`
+}
+
+type Output struct {
+	Path      string   `json:"path"`
+	Format    string   `json:"format"`
+	Stats     bool     `json:"stats"`
+	Clients   bool     `json:"clients"`
+	Histogram bool     `json:"histogram"`
+	Bucket    Duration `json:"bucket"`
+	HTML      string   `json:"html"`
+}
+
+// Duration is a time.Duration written as a string such as "30m" in the
+// config file.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	file := &File{}
+	if err := dec.Decode(file); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Apply overrides cfg with every value set in the file.
+func (f *File) Apply(cfg *Config) error {
+	if f.Tables != nil {
+		cfg.NumberOfTables = *f.Tables
+	}
+	if f.Hours != nil {
+		opening, err := cfg.Time.Parse(f.Hours.Open)
+		if err != nil {
+			return fmt.Errorf("config: invalid opening time %q", f.Hours.Open)
+		}
+		closing, err := cfg.Time.Parse(f.Hours.Close)
+		if err != nil {
+			return fmt.Errorf("config: invalid closing time %q", f.Hours.Close)
+		}
+		cfg.OpeningTime = opening
+		cfg.ClosingTime = closing
+	}
+	if f.Pricing != nil && f.Pricing.HourlyRate != nil {
+		cfg.HourlyRate = *f.Pricing.HourlyRate
+	}
+	if f.Pricing != nil && f.Pricing.Discount != nil {
+		cfg.Discount = *f.Pricing.Discount
+	}
+	if f.Pricing != nil && f.Pricing.Currency != "" {
+		cfg.Currency = f.Pricing.Currency
+	}
+	if f.Pricing != nil && f.Pricing.Tax != nil {
+		cfg.Tax = models.Tax{Rate: f.Pricing.Tax.Rate, Included: f.Pricing.Tax.Included}
+		if f.Pricing.Tax.Rounding != "" {
+			mode, err := models.ParseRounding(f.Pricing.Tax.Rounding)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			cfg.Tax.Rounding = mode
+		}
+	}
+	if f.Zones != nil {
+		cfg.Zones = f.Zones
+	}
+	if f.Queue != nil && f.Queue.Capacity != nil {
+		cfg.QueueCapacity = *f.Queue.Capacity
+	} else {
+		cfg.QueueCapacity = cfg.NumberOfTables
+	}
+	if f.Pause != nil {
+		cfg.MaxPause = time.Duration(f.Pause.Max)
+	}
+	if f.Output != nil {
+		cfg.Output = *f.Output
+	}
+	if f.TimeZone != "" {
+		loc, err := time.LoadLocation(f.TimeZone)
+		if err != nil {
+			return fmt.Errorf("config: unknown time zone %q", f.TimeZone)
+		}
+		cfg.Location = loc
+	}
+	if f.Date != "" {
+		date, err := time.Parse(time.DateOnly, f.Date)
+		if err != nil {
+			return fmt.Errorf("config: invalid date %q", f.Date)
+		}
+		cfg.Date = date
+	}
+	return nil
+}
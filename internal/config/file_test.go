@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		input       string
+		expectedErr string
+		expectedCfg *Config
+	}{
+		{
+			name:  "config file overrides header",
+			json:  `{"tables": 4, "pricing": {"hourly_rate": 20}}`,
+			input: "3\n09:00 19:00\n10\n09:10 1 client\n",
+			expectedCfg: &Config{
+				NumberOfTables: 4,
+				OpeningTime:    time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+				ClosingTime:    time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+				HourlyRate:     2000,
+				QueueCapacity:  4,
+			},
+		},
+		{
+			name:  "config file without header",
+			json:  `{"tables": 2, "hours": {"open": "08:00", "close": "16:00"}, "pricing": {"hourly_rate": 5}, "queue": {"capacity": 1}}`,
+			input: "09:10 1 client\n",
+			expectedCfg: &Config{
+				NumberOfTables: 2,
+				OpeningTime:    time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC),
+				ClosingTime:    time.Date(0, 1, 1, 16, 0, 0, 0, time.UTC),
+				HourlyRate:     500,
+				QueueCapacity:  1,
+			},
+		},
+		{
+			name:        "missing values without header",
+			json:        `{"tables": 2}`,
+			input:       "09:10 1 client\n",
+			expectedErr: "opening hours are not set",
+		},
+		{
+			name:        "invalid header is still reported",
+			json:        `{"pricing": {"hourly_rate": 5}}`,
+			input:       "3\n09:00 19:00\n-1\n",
+			expectedErr: "-1",
+		},
+		{
+			name:        "zone table out of range",
+			json:        `{"zones": [{"name": "vip", "tables": [4], "hourly_rate": 30}]}`,
+			input:       "3\n09:00 19:00\n10\n",
+			expectedErr: `zone "vip": table 4 does not exist`,
+		},
+		{
+			name:        "unknown field",
+			json:        `{"tabels": 3}`,
+			input:       "3\n09:00 19:00\n10\n",
+			expectedErr: "unknown field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "club.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(bufio.NewReader(strings.NewReader(tt.input)), path, utils.TimeFormat{})
+
+			if tt.expectedErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %v, got nil", tt.expectedErr)
+				}
+				if !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("didn't expect error but got %v", err)
+			}
+
+			if cfg.NumberOfTables != tt.expectedCfg.NumberOfTables || !cfg.OpeningTime.Equal(tt.expectedCfg.OpeningTime) ||
+				!cfg.ClosingTime.Equal(tt.expectedCfg.ClosingTime) || cfg.HourlyRate != tt.expectedCfg.HourlyRate ||
+				cfg.QueueCapacity != tt.expectedCfg.QueueCapacity {
+				t.Fatalf("expected config %+v, got %+v", tt.expectedCfg, cfg)
+			}
+			if !cfg.FileScanner.Scan() || cfg.FileScanner.Text() != "09:10 1 client" {
+				t.Fatalf("expected scanner to be positioned at the first event")
+			}
+		})
+	}
+}
+
+func TestRateFor(t *testing.T) {
+	cfg := &Config{
+		HourlyRate: 1000,
+		Zones:      []Zone{{Name: "vip", Tables: []int{2}, HourlyRate: 2550}},
+	}
+	if rate := cfg.RateFor(1); rate != 1000 {
+		t.Errorf("Expected rate 10 for table 1, got %s", rate)
+	}
+	if rate := cfg.RateFor(2); rate != 2550 {
+		t.Errorf("Expected rate 25.50 for table 2, got %s", rate)
+	}
+}
+
+func TestLoadTax(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "club.json")
+	json := `{"pricing": {"hourly_rate": "149.50", "currency": "RUB", "tax": {"rate": 20, "included": true, "rounding": "half_even"}}}`
+	if err := os.WriteFile(path, []byte(json), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(bufio.NewReader(strings.NewReader("3\n09:00 19:00\n10\n")), path, utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.Tax{Rate: 2000, Included: true, Rounding: models.RoundHalfEven}
+	if cfg.HourlyRate != 14950 || cfg.Currency != "RUB" || cfg.Tax != want {
+		t.Errorf("unexpected pricing: rate %s, currency %q, tax %+v", cfg.HourlyRate, cfg.Currency, cfg.Tax)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"pricing": {"tax": {"rate": 20, "rounding": "bankers"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(bufio.NewReader(strings.NewReader("3\n09:00 19:00\n10\n")), path, utils.TimeFormat{}); err == nil {
+		t.Error("expected an error for an unknown rounding mode")
+	}
+}
@@ -0,0 +1,266 @@
+package debugger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Step is the state of the club after one line of the event log. The first
+// step is the opening of the club and the last one its closing, Line is 0
+// for both.
+type Step struct {
+	Line  int
+	Text  string
+	At    time.Time
+	Out   []*models.Event
+	Err   error
+	State *storage.Snapshot
+}
+
+// Debugger processes an event log one line at a time and keeps a snapshot
+// of the club after every line, so it can go back and jump around freely.
+// Lines are only processed when a step needs them.
+type Debugger struct {
+	cfg   *config.Config
+	repo  *storage.InMemRepo
+	h     *handler.FileHandler
+	out   []*models.Event
+	at    time.Time
+	lines []string
+	first int
+	next  int
+	done  bool
+
+	steps []*Step
+	pos   int
+}
+
+// Load reads the event log from r. Input is the format of event lines, as
+// in FileHandler, and tf the format of their timestamps.
+func Load(r io.Reader, configPath, input string, tf utils.TimeFormat) (*Debugger, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var all []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		all = append(all, s.Text())
+	}
+	cfg, err := config.Load(bufio.NewReader(bytes.NewReader(data)), configPath, tf)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for cfg.FileScanner.Scan() {
+		lines = append(lines, cfg.FileScanner.Text())
+	}
+
+	d := &Debugger{
+		cfg:   cfg,
+		repo:  storage.NewInMemRepo(cfg),
+		lines: lines,
+		first: len(all) - len(lines) + 1,
+	}
+	svc := service.New(cfg, d.repo)
+	svc.AddObserver(d)
+	d.h = handler.NewFileHandler(nil, svc, cfg)
+	d.h.Out = io.Discard
+	d.h.Input = input
+	d.at = cfg.OpeningTime
+	d.steps = []*Step{{Text: "opening", At: cfg.OpeningTime, State: d.repo.Snapshot()}}
+	return d, nil
+}
+
+func (d *Debugger) OnEvent(e *models.Event, incoming bool) {
+	if incoming {
+		d.at = e.Timestamp
+	} else {
+		d.out = append(d.out, e)
+	}
+}
+
+func (d *Debugger) OnChange(*models.Change) {}
+
+// Current returns the step the debugger is at.
+func (d *Debugger) Current() *Step {
+	return d.steps[d.pos]
+}
+
+// Next moves one step forward and reports whether there was one.
+func (d *Debugger) Next() bool {
+	if d.pos+1 == len(d.steps) && !d.advance() {
+		return false
+	}
+	d.pos++
+	return true
+}
+
+// Prev moves one step back and reports whether there was one.
+func (d *Debugger) Prev() bool {
+	if d.pos == 0 {
+		return false
+	}
+	d.pos--
+	return true
+}
+
+// GotoLine moves to the last step at or before line n of the input.
+func (d *Debugger) GotoLine(n int) {
+	for !d.done && d.last().Line <= n {
+		d.advance()
+	}
+	d.pos = 0
+	for i, s := range d.steps {
+		if s.Line != 0 && s.Line <= n {
+			d.pos = i
+		}
+	}
+}
+
+// GotoTime moves to the last step at or before t, the state of the club at
+// that time.
+func (d *Debugger) GotoTime(t time.Time) {
+	for !d.done && !d.last().At.After(t) {
+		d.advance()
+	}
+	d.pos = 0
+	for i, s := range d.steps {
+		if !s.At.After(t) {
+			d.pos = i
+		}
+	}
+}
+
+func (d *Debugger) last() *Step {
+	return d.steps[len(d.steps)-1]
+}
+
+// advance processes the next event line, or closes the club after the
+// last one. Comment lines are skipped. The day stops at a line that cannot
+// be processed, just like a run stops there.
+func (d *Debugger) advance() bool {
+	if d.done {
+		return false
+	}
+	for d.next < len(d.lines) && config.IsComment(d.lines[d.next]) {
+		d.next++
+	}
+	d.out = nil
+	if d.next == len(d.lines) {
+		d.h.Report()
+		d.done = true
+		d.steps = append(d.steps, &Step{Text: "closing", At: d.cfg.ClosingTime, Out: d.out, State: d.repo.Snapshot()})
+		return true
+	}
+	text := d.lines[d.next]
+	step := &Step{Line: d.first + d.next, Text: text}
+	d.next++
+	if err := d.h.ProcessLine(text); err != nil {
+		step.Err = err
+		d.done = true
+	}
+	step.At = d.at
+	step.Out = d.out
+	step.State = d.repo.Snapshot()
+	d.steps = append(d.steps, step)
+	return true
+}
+
+// Print writes the step the debugger is at: the line, the events the club
+// logged for it and the state after it.
+func (d *Debugger) Print(w io.Writer) {
+	s := d.Current()
+	if s.Line == 0 {
+		fmt.Fprintf(w, "%s %s\n", s.Text, d.cfg.Time.Format(s.At))
+	} else {
+		fmt.Fprintf(w, "line %d: %s\n", s.Line, s.Text)
+	}
+	if s.Err != nil {
+		fmt.Fprintln(w, "  invalid event, processing stops here")
+	}
+	for _, e := range s.Out {
+		fmt.Fprintf(w, "  %s\n", e.Format(d.cfg.Time))
+	}
+
+	s.State.Write(w, d.cfg.Time)
+	var tables models.Money
+	for _, t := range s.State.Tables {
+		tables += d.cfg.RateFor(t.Id).Times(models.BillableHours(t.TotalTime))
+	}
+	var extras models.Money
+	for _, e := range s.State.Extras {
+		extras += e.Sum()
+	}
+	fmt.Fprintf(w, "revenue: %s (tables %s, extras %s)\n", tables+extras, tables, extras)
+}
+
+const help = `commands:
+  n, next         process the next line (also an empty line)
+  b, back         go back one step
+  l, line <n>     go to the state after line n of the input
+  t, time <time>  go to the state at a time
+  p, print        show the current step again
+  q, quit         stop debugging
+`
+
+// Run reads commands from in and writes the steps to out until quit or the
+// end of in.
+func (d *Debugger) Run(in io.Reader, out io.Writer) error {
+	d.Print(out)
+	s := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !s.Scan() {
+			fmt.Fprintln(out)
+			return s.Err()
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(s.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		switch cmd {
+		case "", "n", "next":
+			if !d.Next() {
+				fmt.Fprintln(out, "no more steps")
+				continue
+			}
+		case "b", "back":
+			if !d.Prev() {
+				fmt.Fprintln(out, "already at the opening")
+				continue
+			}
+		case "l", "line":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				fmt.Fprintln(out, "usage: line <n>")
+				continue
+			}
+			d.GotoLine(n)
+		case "t", "time":
+			t, err := d.cfg.Time.Parse(arg)
+			if err != nil {
+				fmt.Fprintln(out, "usage: time <time>")
+				continue
+			}
+			d.GotoTime(d.cfg.Place(t, time.Time{}))
+		case "p", "print":
+		case "q", "quit":
+			return nil
+		default:
+			fmt.Fprint(out, help)
+			continue
+		}
+		d.Print(out)
+	}
+}
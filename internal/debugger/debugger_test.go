@@ -0,0 +1,110 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const day = `2
+09:00 19:00
+10
+09:10 1 alice
+09:20 2 alice 1
+# bob wants a table too
+09:30 1 bob
+09:31 2 bob 1
+09:40 10 alice cola 2.50 2
+10:00 4 alice
+`
+
+func load(t *testing.T, log string) *Debugger {
+	t.Helper()
+	d, err := Load(strings.NewReader(log), "", "", utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestSteps(t *testing.T) {
+	d := load(t, day)
+	var lines []int
+	for d.Next() {
+		lines = append(lines, d.Current().Line)
+	}
+	want := []int{4, 5, 7, 8, 9, 10, 0}
+	if len(lines) != len(want) {
+		t.Fatalf("got lines %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("got lines %v, want %v", lines, want)
+		}
+	}
+
+	d.GotoLine(6)
+	s := d.Current()
+	if s.Line != 5 || s.State.Tables[0].Client == nil || s.State.Tables[0].Client.Name != "alice" {
+		t.Errorf("line 6: got step at line %d with table 1 %+v", s.Line, s.State.Tables[0])
+	}
+	if !d.Prev() || d.Current().Line != 4 || d.Current().State.Tables[0].Client != nil {
+		t.Errorf("back from line 5: got step at line %d", d.Current().Line)
+	}
+
+	d.GotoTime(time.Date(0, 1, 1, 9, 35, 0, 0, time.UTC))
+	s = d.Current()
+	if s.Line != 8 || len(s.Out) != 1 || s.Out[0].ErrorMsg.Error() != "PlaceIsBusy" {
+		t.Errorf("09:35: got step at line %d with %v", s.Line, s.Out)
+	}
+	if strings.Join(s.State.Clients, ",") != "alice,bob" {
+		t.Errorf("09:35: got clients %v", s.State.Clients)
+	}
+}
+
+func TestRun(t *testing.T) {
+	d := load(t, day)
+	var out strings.Builder
+	if err := d.Run(strings.NewReader("l 10\nb\nx\nq\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := `opening 09:00
+tables:
+  1 free
+  2 free
+queue: empty
+clients: empty
+revenue: 0 (tables 0, extras 0)
+> line 10: 10:00 4 alice
+tables:
+  1 free
+  2 free
+queue: empty
+clients: bob
+revenue: 15 (tables 10, extras 5)
+> line 9: 09:40 10 alice cola 2.50 2
+tables:
+  1 alice since 09:20
+  2 free
+queue: empty
+clients: alice, bob
+revenue: 5 (tables 0, extras 5)
+> ` + help + "> "
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestInvalidLineStops(t *testing.T) {
+	d := load(t, "1\n09:00 19:00\n10\n09:10 1 alice\n09:20 x alice\n09:30 1 bob\n")
+	d.GotoLine(10)
+	s := d.Current()
+	if s.Line != 5 || s.Err == nil {
+		t.Errorf("got step at line %d with error %v", s.Line, s.Err)
+	}
+	if d.Next() {
+		t.Error("stepped past an invalid line")
+	}
+}
@@ -0,0 +1,54 @@
+package follow
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Reader reads a file that is still being written. At the end of the file
+// it waits for more data instead of returning io.EOF, until ctx is done.
+type Reader struct {
+	ctx      context.Context
+	r        io.Reader
+	interval time.Duration
+}
+
+func NewReader(ctx context.Context, r io.Reader, interval time.Duration) *Reader {
+	return &Reader{
+		ctx:      ctx,
+		r:        r,
+		interval: interval,
+	}
+}
+
+func (f *Reader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// IsClosingMarker reports whether the line is a bare time in the format tf,
+// which closes the day the same way it ends the output.
+func IsClosingMarker(line string, tf utils.TimeFormat) bool {
+	line = strings.TrimSpace(line)
+	if strings.Contains(line, " ") {
+		return false
+	}
+	_, err := tf.Parse(line)
+	return err == nil
+}
@@ -0,0 +1,75 @@
+package follow
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func TestReaderWaitsForData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The source behaves like a file: it reports EOF whenever nothing new
+	// has been appended yet.
+	lines := make(chan string, 1)
+	src := &eofReader{data: lines}
+	scanner := bufio.NewScanner(NewReader(ctx, src, 5*time.Millisecond))
+
+	go func() {
+		lines <- "10:00 1 anna\n"
+		time.Sleep(20 * time.Millisecond)
+		lines <- "10:05 1 boris\n"
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	var got []string
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if len(got) != 2 || got[0] != "10:00 1 anna" || got[1] != "10:05 1 boris" {
+		t.Errorf("Expected both lines, got %v", got)
+	}
+}
+
+type eofReader struct {
+	data chan string
+	buf  string
+}
+
+func (e *eofReader) Read(p []byte) (int, error) {
+	select {
+	case s := <-e.data:
+		e.buf += s
+	default:
+	}
+	if e.buf == "" {
+		return 0, io.EOF
+	}
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	return n, nil
+}
+
+func TestIsClosingMarker(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"19:00", true},
+		{" 19:00 ", true},
+		{"19:00 1 anna", false},
+		{"3", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsClosingMarker(tt.line, utils.TimeFormat{}); got != tt.expected {
+			t.Errorf("IsClosingMarker(%q) = %v, expected %v", tt.line, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package generator
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Model describes a club day to generate. Arrivals are a Poisson process
+// whose hourly rate is taken from ArrivalRates, one value per opening hour;
+// the last value is reused for the remaining hours. Session lengths are
+// normally distributed, or exponential when SessionStdDev is zero.
+type Model struct {
+	Seed          int64
+	Tables        int
+	OpeningTime   time.Time
+	ClosingTime   time.Time
+	HourlyRate    int
+	ArrivalRates  []float64
+	SessionMean   time.Duration
+	SessionStdDev time.Duration
+	WaitProb      float64
+	ErrorProb     float64
+}
+
+func (m *Model) Validate() error {
+	if m.Tables < 1 {
+		return errors.New("number of tables must be positive")
+	}
+	if !m.ClosingTime.After(m.OpeningTime) {
+		return errors.New("closing time must be after opening time")
+	}
+	if m.HourlyRate < 1 {
+		return errors.New("hourly rate must be positive")
+	}
+	if len(m.ArrivalRates) == 0 {
+		return errors.New("arrival rate is not set")
+	}
+	for _, r := range m.ArrivalRates {
+		if r < 0 {
+			return errors.New("arrival rate must not be negative")
+		}
+	}
+	if m.SessionMean <= 0 || m.SessionStdDev < 0 {
+		return errors.New("session length must be positive")
+	}
+	if m.WaitProb < 0 || m.WaitProb > 1 || m.ErrorProb < 0 || m.ErrorProb > 1 {
+		return errors.New("probabilities must be between 0 and 1")
+	}
+	return nil
+}
+
+type departure struct {
+	at     time.Time
+	client string
+}
+
+type departures []departure
+
+func (d departures) Len() int           { return len(d) }
+func (d departures) Less(i, j int) bool { return d[i].at.Before(d[j].at) }
+func (d departures) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d *departures) Push(x any)        { *d = append(*d, x.(departure)) }
+func (d *departures) Pop() any {
+	old := *d
+	x := old[len(old)-1]
+	*d = old[:len(old)-1]
+	return x
+}
+
+type generator struct {
+	m       *Model
+	rnd     *rand.Rand
+	tables  []string
+	queue   []string
+	pending departures
+	lines   []string
+	ghosts  int
+}
+
+// Generate writes an input file for the model. The same model, seed
+// included, always produces the same file.
+func Generate(w io.Writer, m Model) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	g := &generator{
+		m:      &m,
+		rnd:    rand.New(rand.NewSource(m.Seed)),
+		tables: make([]string, m.Tables),
+	}
+	g.run()
+
+	fmt.Fprintln(w, m.Tables)
+	fmt.Fprintf(w, "%s %s\n", utils.Format(m.OpeningTime), utils.Format(m.ClosingTime))
+	fmt.Fprintln(w, m.HourlyRate)
+	for _, l := range g.lines {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) run() {
+	day := g.m.OpeningTime.Truncate(24 * time.Hour)
+	early := g.m.OpeningTime.Add(-30 * time.Minute)
+	if early.Before(day) {
+		early = day
+	}
+	for _, at := range g.arrivals(early, g.m.OpeningTime) {
+		if g.rnd.Float64() < g.m.ErrorProb {
+			g.emit(at, "%d %s", 1, g.ghost())
+		}
+	}
+
+	// The engine rejects arrivals in the opening minute itself.
+	names := 0
+	for _, at := range g.arrivals(g.m.OpeningTime.Add(time.Minute), g.m.ClosingTime) {
+		g.departUntil(at)
+		names++
+		g.arrive(at, fmt.Sprintf("client%d", names))
+	}
+	g.departUntil(g.m.ClosingTime)
+}
+
+func (g *generator) arrivals(from, to time.Time) []time.Time {
+	var out []time.Time
+	at := from
+	for hour := 0; at.Before(to); hour++ {
+		rate := g.m.ArrivalRates[min(hour, len(g.m.ArrivalRates)-1)]
+		end := g.m.OpeningTime.Add(time.Duration(hour+1) * time.Hour)
+		if from.Before(g.m.OpeningTime) {
+			end = g.m.OpeningTime
+		}
+		if end.After(to) {
+			end = to
+		}
+		for rate > 0 {
+			gap := time.Duration(g.rnd.ExpFloat64() / rate * float64(time.Hour))
+			if !at.Add(gap).Before(end) {
+				break
+			}
+			at = at.Add(gap)
+			out = append(out, at)
+		}
+		at = end
+	}
+	return out
+}
+
+func (g *generator) arrive(at time.Time, name string) {
+	g.emit(at, "%d %s", 1, name)
+
+	if g.rnd.Float64() < g.m.ErrorProb {
+		g.provoke(at, name)
+	}
+
+	free := g.freeTables()
+	if len(free) > 0 {
+		g.sit(at, name, free[g.rnd.Intn(len(free))])
+		return
+	}
+	if g.rnd.Float64() >= g.m.WaitProb {
+		g.leave(at, name)
+		return
+	}
+	g.emit(at, "%d %s", 3, name)
+	if len(g.queue) == g.m.Tables {
+		return
+	}
+	g.queue = append(g.queue, name)
+}
+
+// provoke emits one event the engine has to reject.
+func (g *generator) provoke(at time.Time, name string) {
+	busy := g.busyTables()
+	switch g.rnd.Intn(4) {
+	case 0:
+		g.emit(at, "%d %s", 1, name)
+	case 1:
+		if len(busy) > 0 {
+			g.emit(at, "%d %s %d", 2, name, busy[g.rnd.Intn(len(busy))]+1)
+			return
+		}
+		fallthrough
+	case 2:
+		g.emit(at, "%d %s %d", 2, g.ghost(), g.rnd.Intn(g.m.Tables)+1)
+	case 3:
+		if len(busy) < g.m.Tables {
+			g.emit(at, "%d %s", 3, name)
+			return
+		}
+		g.emit(at, "%d %s", 4, g.ghost())
+	}
+}
+
+func (g *generator) sit(at time.Time, name string, table int) {
+	g.emit(at, "%d %s %d", 2, name, table+1)
+	g.seat(at, name, table)
+}
+
+func (g *generator) seat(at time.Time, name string, table int) {
+	g.tables[table] = name
+	heap.Push(&g.pending, departure{at: at.Add(g.sessionLength()), client: name})
+}
+
+func (g *generator) leave(at time.Time, name string) {
+	g.emit(at, "%d %s", 4, name)
+	if i := slices.Index(g.queue, name); i >= 0 {
+		g.queue = slices.Delete(g.queue, i, i+1)
+	}
+	if i := slices.Index(g.tables, name); i >= 0 {
+		g.tables[i] = ""
+		if len(g.queue) > 0 {
+			next := g.queue[0]
+			g.queue = g.queue[1:]
+			g.seat(at, next, i)
+		}
+	}
+}
+
+func (g *generator) departUntil(at time.Time) {
+	for g.pending.Len() > 0 && !g.pending[0].at.After(at) {
+		d := heap.Pop(&g.pending).(departure)
+		if !d.at.Before(g.m.ClosingTime) {
+			continue
+		}
+		g.leave(d.at, d.client)
+	}
+}
+
+func (g *generator) sessionLength() time.Duration {
+	var minutes float64
+	mean := g.m.SessionMean.Minutes()
+	if g.m.SessionStdDev == 0 {
+		minutes = g.rnd.ExpFloat64() * mean
+	} else {
+		minutes = g.rnd.NormFloat64()*g.m.SessionStdDev.Minutes() + mean
+	}
+	return time.Duration(math.Max(minutes, 1) * float64(time.Minute))
+}
+
+func (g *generator) freeTables() []int {
+	var free []int
+	for i, name := range g.tables {
+		if name == "" {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+func (g *generator) busyTables() []int {
+	var busy []int
+	for i, name := range g.tables {
+		if name != "" {
+			busy = append(busy, i)
+		}
+	}
+	return busy
+}
+
+func (g *generator) ghost() string {
+	g.ghosts++
+	return fmt.Sprintf("ghost%d", g.ghosts)
+}
+
+func (g *generator) emit(at time.Time, format string, args ...any) {
+	g.lines = append(g.lines, utils.Format(at)+" "+fmt.Sprintf(format, args...))
+}
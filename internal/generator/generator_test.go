@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func model(seed int64) Model {
+	opening, _ := utils.Parse("09:00")
+	closing, _ := utils.Parse("21:00")
+	return Model{
+		Seed:          seed,
+		Tables:        3,
+		OpeningTime:   opening,
+		ClosingTime:   closing,
+		HourlyRate:    10,
+		ArrivalRates:  []float64{2, 4, 8, 3},
+		SessionMean:   80 * time.Minute,
+		SessionStdDev: 40 * time.Minute,
+		WaitProb:      0.6,
+		ErrorProb:     0.2,
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	var a, b, c bytes.Buffer
+	if err := Generate(&a, model(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(&b, model(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(&c, model(43)); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != b.String() {
+		t.Errorf("Expected the same seed to produce the same file")
+	}
+	if a.String() == c.String() {
+		t.Errorf("Expected different seeds to produce different files")
+	}
+}
+
+func TestGenerateFollowsInputFormat(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		m := model(seed)
+		if seed%2 == 0 {
+			m.SessionStdDev = 0
+		}
+		var buf bytes.Buffer
+		if err := Generate(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+
+		prev := m.OpeningTime.Add(-time.Hour)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		for _, line := range lines[3:] {
+			fields := strings.Split(line, " ")
+			ts, err := utils.Parse(fields[0])
+			if err != nil || ts.Before(prev) || utils.Format(ts) != fields[0] {
+				t.Fatalf("seed %d: event out of order or malformed: %q", seed, line)
+			}
+			if !models.ValidClientName.MatchString(fields[2]) {
+				t.Fatalf("seed %d: invalid client name: %q", seed, line)
+			}
+			prev = ts
+		}
+
+		scanner := bufio.NewScanner(&buf)
+		cfg, err := config.NewConfig(scanner, utils.TimeFormat{})
+		if err != nil {
+			t.Fatalf("seed %d: invalid header: %v", seed, err)
+		}
+		repo := storage.NewInMemRepo(cfg)
+		h := handler.NewFileHandler(scanner, service.New(cfg, repo), cfg)
+		if err := h.ProcessEvents(); err != nil {
+			t.Fatalf("seed %d: invalid event line: %v", seed, err)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -12,15 +15,52 @@ import (
 
 	"github.com/Korpenter/club/internal/config"
 	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/report"
 	"github.com/Korpenter/club/internal/service"
 	"github.com/Korpenter/club/internal/utils"
 )
 
 type FileHandler struct {
-	Scanner *bufio.Scanner
-	Service Service
-	cfg     *config.Config
-	ee      []*models.Event
+	Scanner  *bufio.Scanner
+	Service  Service
+	Registry *Registry
+	Out      io.Writer
+
+	// Input is the format of event lines: text, jsonl, or auto to tell
+	// them apart line by line.
+	Input string
+
+	// VisitEnded is called for every visit ended by a departure or by
+	// closing, right after the event that ended it.
+	VisitEnded func(v *models.Visit)
+
+	// VisitVoided is called for a visit passed to VisitEnded that a
+	// correction has undone. A visit a correction has changed is passed to
+	// VisitEnded again.
+	VisitVoided func(v *models.Visit)
+
+	// Check is called after every incoming event is applied, and at closing
+	// after the tables are released and the clients sent away. An error it
+	// returns stops processing.
+	Check func(e *models.Event) error
+
+	cfg    *config.Config
+	ee     []*models.Event
+	stream bool
+
+	// incoming holds the incoming events in input order, the sequence
+	// number of an event is its index plus one.
+	incoming    []*models.Event
+	amended     map[int]*models.Event
+	corrections []*models.Correction
+
+	// ended holds the visits ended so far. They are not reported while the
+	// day is replayed after a correction.
+	ended     []*models.Visit
+	replaying bool
+
+	// closingErr is the error Check returned at closing.
+	closingErr error
 }
 
 type Service interface {
@@ -28,141 +68,317 @@ type Service interface {
 	ClientWait(timestamp time.Time, name string) error
 	ClientLeave(timestamp time.Time, name string) (*models.Client, int, error)
 	ClientSit(timestamp time.Time, name string, tableID int) error
+	TableOutOfService(timestamp time.Time, tableID int) (*models.Client, int, error)
+	TableInService(timestamp time.Time, tableID int) (*models.Client, error)
+	ClientOrder(extra *models.Extra) error
+	ClientPause(timestamp time.Time, name string) error
+	ClientResume(timestamp time.Time, name string) error
+	ReleaseExpired(now time.Time) []*service.Release
 	KickClients(kickTime time.Time) []*models.Client
 	CalcProfits() []*models.Profit
+	Sessions() []*models.Session
+	Waits() []*models.Wait
+	Visits() []*models.Visit
+	Extras() []*models.Extra
+	EndedVisits() []*models.Visit
+	NotifyEvent(e *models.Event, incoming bool)
+	Replay(fn func())
 }
 
 func NewFileHandler(scanner *bufio.Scanner, service Service, cfg *config.Config) *FileHandler {
 	return &FileHandler{
-		Scanner: scanner,
-		Service: service,
-		cfg:     cfg,
+		Scanner:  scanner,
+		Service:  service,
+		Registry: DefaultRegistry(),
+		Out:      os.Stdout,
+		cfg:      cfg,
 	}
 }
 
 func (h *FileHandler) ProcessEvents() error {
 	for h.Scanner.Scan() {
-		eventString := h.Scanner.Text()
-		eventSplit := strings.Split(h.Scanner.Text(), " ")
-		if len(eventSplit) < 3 || len(eventSplit) > 5 {
-			return errors.New(eventString)
+		if err := h.ProcessLine(h.Scanner.Text()); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		eventTime, err := utils.Parse(eventSplit[0])
-		if err != nil {
+// StartStream writes the opening time right away and makes every following
+// event be written as soon as it is logged. EndDay then only writes what
+// happens at closing.
+func (h *FileHandler) StartStream() {
+	h.stream = true
+	fmt.Fprintln(h.Out, h.cfg.Time.Format(h.cfg.OpeningTime))
+}
+
+// ProcessLine parses and applies one line of the event log. Comment lines
+// are skipped.
+func (h *FileHandler) ProcessLine(eventString string) error {
+	if config.IsComment(eventString) {
+		return nil
+	}
+	event, err := h.ParseLine(eventString)
+	if err != nil {
+		return errors.New(eventString)
+	}
+	h.incoming = append(h.incoming, event)
+	if event.Code == models.EventCorrection {
+		if err := h.correct(event); err != nil {
+			h.incoming = h.incoming[:len(h.incoming)-1]
 			return errors.New(eventString)
 		}
+	} else {
+		h.apply(event)
+	}
+	if h.Check != nil {
+		return h.Check(event)
+	}
+	return nil
+}
+
+// ParseLine parses an event line in the input format without applying it.
+func (h *FileHandler) ParseLine(line string) (*models.Event, error) {
+	fields := strings.Split(line, " ")
+	if h.Input == InputJSONL || (h.Input != InputText && isJSONLine(line)) {
+		var err error
+		if fields, err = parseJSONLine(line); err != nil {
+			return nil, err
+		}
+	}
+	return h.parseEvent(fields, true)
+}
 
-		eventCode, err := strconv.Atoi(eventSplit[1])
+// parseEvent parses the fields of an event line. A correction carries the
+// replaced event in its own fields, corrections of corrections are not
+// allowed.
+func (h *FileHandler) parseEvent(fields []string, correction bool) (*models.Event, error) {
+	if len(fields) < 2 {
+		return nil, errInvalidField
+	}
+	eventTime, err := h.cfg.Time.Parse(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	var last time.Time
+	if len(h.incoming) > 0 {
+		last = h.incoming[len(h.incoming)-1].Timestamp
+	}
+	eventTime = h.cfg.Place(eventTime, last)
+	if utils.HasDate(eventTime) != utils.HasDate(h.cfg.OpeningTime) {
+		return nil, errInvalidField
+	}
+	eventCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	event := &models.Event{
+		Code:      eventCode,
+		Timestamp: eventTime,
+	}
+	args := fields[2:]
+
+	if eventCode == models.EventCorrection {
+		if !correction || len(args) < 2 {
+			return nil, errInvalidField
+		}
+		event.Ref, err = strconv.Atoi(args[0])
 		if err != nil {
-			return errors.New(eventString)
+			return nil, err
+		}
+		if len(args) == 2 && args[1] == "void" {
+			return event, nil
+		}
+		event.Amend, err = h.parseEvent(args[1:], false)
+		if err != nil {
+			return nil, err
 		}
+		return event, nil
+	}
 
-		if !models.ValidClientName.MatchString(eventSplit[2]) {
-			return errors.New(eventString)
+	eventType, ok := h.Registry.Lookup(eventCode)
+	if !ok {
+		return nil, errInvalidField
+	}
+	if len(args) < eventType.MinArgs || len(args) > eventType.MaxArgs {
+		return nil, errInvalidField
+	}
+	if err := eventType.Parse(h.cfg, event, args); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (h *FileHandler) apply(event *models.Event) {
+	eventType, _ := h.Registry.Lookup(event.Code)
+	h.release(event.Timestamp)
+	h.logEvent(event, true)
+	for _, out := range eventType.Handle(h.Service, event) {
+		h.logEvent(out, false)
+	}
+	h.settle()
+}
+
+func (h *FileHandler) settle() {
+	for _, v := range h.Service.EndedVisits() {
+		h.ended = append(h.ended, v)
+		if h.VisitEnded != nil && !h.replaying {
+			h.VisitEnded(v)
 		}
-		clientName := eventSplit[2]
-		event := &models.Event{
-			Code:       eventCode,
-			Timestamp:  eventTime,
-			ClientName: clientName,
-		}
-		switch eventCode {
-		case models.ClientArrived:
-			h.logEvent(event)
-			err := h.Service.ClientArrive(eventTime, clientName)
-			if err != nil {
-				errEvent := &models.Event{
-					Code:      models.EventError,
-					Timestamp: eventTime,
-					ErrorMsg:  err,
-				}
-				h.logEvent(errEvent)
-			}
-		case models.ClientSat:
-			if len(eventSplit) < 4 {
-				return errors.New(eventString)
-			}
-			tableID, err := strconv.Atoi(eventSplit[3])
-			if err != nil {
-				return errors.New(eventString)
+	}
+}
+
+// reconcile reports how a correction changed the visits ended before it:
+// visits that are gone are voided, new and changed ones are ended again. A
+// visit is told apart by its client and arrival time.
+func (h *FileHandler) reconcile(before []*models.Visit) {
+	type key struct {
+		name    string
+		arrived time.Time
+	}
+	after := make(map[key]*models.Visit, len(h.ended))
+	for _, v := range h.ended {
+		after[key{v.ClientName, v.Arrived}] = v
+	}
+	same := make(map[*models.Visit]bool)
+	for _, v := range before {
+		w, ok := after[key{v.ClientName, v.Arrived}]
+		switch {
+		case !ok:
+			if h.VisitVoided != nil {
+				h.VisitVoided(v)
 			}
-			event.TableID = tableID
-			h.logEvent(event)
-			err = h.Service.ClientSit(eventTime, clientName, tableID)
-			if err != nil {
-				errEvent := &models.Event{
-					Code:      models.EventError,
-					Timestamp: eventTime,
-					ErrorMsg:  err,
+		case reflect.DeepEqual(v, w):
+			same[w] = true
+		}
+	}
+	for _, v := range h.ended {
+		if !same[v] && h.VisitEnded != nil {
+			h.VisitEnded(v)
+		}
+	}
+}
+
+// correct records a correction and recomputes the day from the corrected
+// log. An amended event keeps its place in the log, so its time must stay
+// between the events around it and not be later than the correction.
+func (h *FileHandler) correct(c *models.Event) error {
+	seq := len(h.incoming)
+	if c.Ref < 1 || c.Ref >= seq || h.incoming[c.Ref-1].Code == models.EventCorrection {
+		return errInvalidField
+	}
+	if c.Amend != nil {
+		if c.Amend.Timestamp.After(c.Timestamp) {
+			return errInvalidField
+		}
+		for i := c.Ref - 1; i >= 1; i-- {
+			if e := h.effective(i); e != nil {
+				if e.Timestamp.After(c.Amend.Timestamp) {
+					return errInvalidField
 				}
-				h.logEvent(errEvent)
+				break
 			}
-		case models.ClientWaiting:
-			h.logEvent(event)
-			err := h.Service.ClientWait(eventTime, clientName)
-			if err != nil {
-				if errors.Is(err, service.ErrICanWaitNoLonger) {
-					errEvent := &models.Event{
-						Code:      models.EventError,
-						Timestamp: eventTime,
-						ErrorMsg:  err,
-					}
-					h.logEvent(errEvent)
-				}
-				if errors.Is(err, service.ErrQueueFull) {
-					leftEvent := &models.Event{
-						Code:       models.ClientForceLeft,
-						Timestamp:  eventTime,
-						ClientName: clientName,
-					}
-					h.logEvent(leftEvent)
+		}
+		for i := c.Ref + 1; i < seq; i++ {
+			if e := h.effective(i); e != nil {
+				if e.Timestamp.Before(c.Amend.Timestamp) {
+					return errInvalidField
 				}
+				break
 			}
-		case models.ClientLeft:
-			h.logEvent(event)
-			dequeued, tableID, err := h.Service.ClientLeave(eventTime, clientName)
-			if err != nil && errors.Is(err, service.ErrClientUnknown) {
-				errEvent := &models.Event{
-					Code:      models.EventError,
-					Timestamp: eventTime,
-					ErrorMsg:  err,
-				}
-				h.logEvent(errEvent)
+		}
+	}
+
+	if h.amended == nil {
+		h.amended = make(map[int]*models.Event)
+	}
+	h.amended[c.Ref] = c.Amend
+	h.corrections = append(h.corrections, &models.Correction{
+		Seq:       seq,
+		Ref:       c.Ref,
+		Timestamp: c.Timestamp,
+		Original:  h.incoming[c.Ref-1],
+		Amended:   c.Amend,
+	})
+
+	stream, before := h.stream, h.ended
+	h.stream, h.ended, h.replaying = false, nil, true
+	h.Service.Replay(func() {
+		h.ee = nil
+		for i, e := range h.incoming {
+			if e.Code == models.EventCorrection {
+				h.logEvent(e, true)
+				continue
 			}
-			if dequeued != nil {
-				sitEvent := &models.Event{
-					Code:       models.ClientSat,
-					Timestamp:  eventTime,
-					ClientName: dequeued.Name,
-					TableID:    tableID,
-				}
-				h.logEvent(sitEvent)
+			if e = h.effective(i + 1); e != nil {
+				h.apply(e)
 			}
-		default:
-			return errors.New(eventString)
 		}
+	})
+	h.stream, h.replaying = stream, false
+	h.reconcile(before)
+	if h.stream {
+		fmt.Fprintln(h.Out, c.Format(h.cfg.Time))
+	}
+	h.Service.NotifyEvent(c, true)
+	return nil
+}
+
+// effective returns the event with sequence number seq as it stands after
+// corrections, or nil for a voided event or a correction.
+func (h *FileHandler) effective(seq int) *models.Event {
+	if e, ok := h.amended[seq]; ok {
+		return e
+	}
+	if e := h.incoming[seq-1]; e.Code != models.EventCorrection {
+		return e
 	}
 	return nil
 }
 
 func (h *FileHandler) EndDay() error {
-	fmt.Println(utils.Format(h.cfg.OpeningTime))
+	day := h.Report()
+	if h.closingErr != nil {
+		return h.closingErr
+	}
+	if err := report.Write(h.Out, h.cfg.Output.Format, day); err != nil {
+		return err
+	}
+	if h.cfg.Output.HTML != "" {
+		f, err := os.Create(h.cfg.Output.HTML)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return report.WriteHTML(f, day)
+	}
+	return nil
+}
+
+func (h *FileHandler) Report() *report.Day {
+	logged := len(h.ee)
+	h.release(h.cfg.ClosingTime)
+	if len(h.ee) > logged {
+		h.checkClosing(h.ee[len(h.ee)-1])
+	}
 	kicked := h.Service.KickClients(h.cfg.ClosingTime)
+	h.settle()
 	cmp := func(a, b *models.Client) int {
 		return strings.Compare(a.Name, b.Name)
 	}
 	slices.SortFunc(kicked, cmp)
-	for _, v := range h.ee {
-		fmt.Println(v)
-	}
+	events := slices.Clone(h.ee)
 	for _, v := range kicked {
 		kickEvent := &models.Event{
 			Code:       models.ClientForceLeft,
 			Timestamp:  h.cfg.ClosingTime,
 			ClientName: v.Name,
 		}
-		fmt.Println(kickEvent)
+		events = append(events, kickEvent)
+		h.Service.NotifyEvent(kickEvent, false)
+	}
+	if len(kicked) > 0 {
+		h.checkClosing(events[len(events)-1])
 	}
 	profits := h.Service.CalcProfits()
 	cmpInt := func(a, b *models.Profit) int {
@@ -174,14 +390,101 @@ func (h *FileHandler) EndDay() error {
 		}
 		return 0
 	}
-	fmt.Println(utils.Format(h.cfg.ClosingTime))
 	slices.SortFunc(profits, cmpInt)
-	for _, v := range profits {
-		fmt.Println(v)
+	day := &report.Day{
+		Time:     h.cfg.Time,
+		Opening:  h.cfg.OpeningTime,
+		Closing:  h.cfg.ClosingTime,
+		Events:   events,
+		Profits:  profits,
+		Sessions: h.Service.Sessions(),
+		Waits:    h.Service.Waits(),
+
+		Corrections: h.corrections,
+	}
+	if h.stream {
+		day.Streamed = true
+		day.StreamedEvents = len(h.ee)
+	}
+	extras, discounts := h.Service.Extras(), h.discounts()
+	if len(extras) > 0 || len(discounts) > 0 {
+		day.Revenue = report.NewRevenue(profits, extras, discounts)
+	}
+	if h.cfg.Tax.Rate > 0 {
+		day.Tax = report.NewTaxes(h.cfg.Tax, h.cfg.Currency, report.NewRevenue(profits, extras, discounts))
+	}
+	if h.cfg.Output.Stats {
+		tables := make([]*models.Table, 0, len(profits))
+		for _, p := range profits {
+			tables = append(tables, p.Table)
+		}
+		day.Stats = report.NewStats(h.cfg.OpeningTime, h.cfg.ClosingTime, tables,
+			h.Service.Sessions(), h.Service.Waits(), h.ee)
+	}
+	if h.cfg.Output.Clients {
+		day.Clients = report.NewClientSummaries(h.Service.Visits(), h.ee, h.cfg.RateFor)
+	}
+	if h.cfg.Output.Histogram {
+		size := time.Duration(h.cfg.Output.Bucket)
+		if size <= 0 {
+			size = time.Hour
+		}
+		day.Histogram = report.NewHistogram(h.cfg.OpeningTime, h.cfg.ClosingTime, size,
+			h.Service.Sessions(), h.Service.Waits(), h.cfg.RateFor)
+	}
+	return day
+}
+
+// discounts sums the discounts the receipts give on table time by table.
+// It is empty if no visit earned one.
+func (h *FileHandler) discounts() map[int]models.Money {
+	discounts := make(map[int]models.Money)
+	for _, v := range h.Service.Visits() {
+		for _, s := range v.Sessions {
+			if amount := h.cfg.Discount.Amount(h.cfg.RateFor(s.TableID).Times(s.Hours), v.Hours()); amount > 0 {
+				discounts[s.TableID] += amount
+			}
+		}
+	}
+	return discounts
+}
+
+// ClosingErr returns the error Check returned for the state at closing,
+// once Report has run.
+func (h *FileHandler) ClosingErr() error {
+	return h.closingErr
+}
+
+func (h *FileHandler) checkClosing(e *models.Event) {
+	if h.Check != nil && h.closingErr == nil {
+		h.closingErr = h.Check(e)
 	}
-	return nil
 }
 
-func (h *FileHandler) logEvent(event *models.Event) {
+// release logs the tables given up after the maximum pause up to now.
+func (h *FileHandler) release(now time.Time) {
+	for _, r := range h.Service.ReleaseExpired(now) {
+		h.logEvent(&models.Event{
+			Code:       models.TableReleased,
+			Timestamp:  r.At,
+			ClientName: r.ClientName,
+			TableID:    r.TableID,
+		}, false)
+		if r.Seated != nil {
+			h.logEvent(&models.Event{
+				Code:       models.ClientSatFromQueue,
+				Timestamp:  r.At,
+				ClientName: r.Seated.Name,
+				TableID:    r.TableID,
+			}, false)
+		}
+	}
+}
+
+func (h *FileHandler) logEvent(event *models.Event, incoming bool) {
 	h.ee = append(h.ee, event)
+	h.Service.NotifyEvent(event, incoming)
+	if h.stream {
+		fmt.Fprintln(h.Out, event.Format(h.cfg.Time))
+	}
 }
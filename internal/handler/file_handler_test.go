@@ -3,6 +3,9 @@ package handler
 import (
 	"bufio"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -10,7 +13,9 @@ import (
 
 	"github.com/Korpenter/club/internal/config"
 	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/receipt"
 	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
 	"github.com/Korpenter/club/internal/utils"
 )
 
@@ -41,6 +46,38 @@ func (m *MockService) ClientSit(timestamp time.Time, name string, tableID int) e
 	return m.SitError
 }
 
+func (m *MockService) TableOutOfService(timestamp time.Time, tableID int) (*models.Client, int, error) {
+	return m.Dequeued, m.FreedTable, m.WaitError
+}
+
+func (m *MockService) TableInService(timestamp time.Time, tableID int) (*models.Client, error) {
+	return m.Dequeued, nil
+}
+
+func (m *MockService) ClientOrder(extra *models.Extra) error {
+	return nil
+}
+
+func (m *MockService) Extras() []*models.Extra {
+	return nil
+}
+
+func (m *MockService) EndedVisits() []*models.Visit {
+	return nil
+}
+
+func (m *MockService) ClientPause(timestamp time.Time, name string) error {
+	return nil
+}
+
+func (m *MockService) ClientResume(timestamp time.Time, name string) error {
+	return nil
+}
+
+func (m *MockService) ReleaseExpired(now time.Time) []*service.Release {
+	return nil
+}
+
 func (m *MockService) KickClients(kickTime time.Time) []*models.Client {
 	return m.Kicked
 }
@@ -48,8 +85,26 @@ func (m *MockService) CalcProfits() []*models.Profit {
 	return m.Profits
 }
 
+func (m *MockService) Sessions() []*models.Session {
+	return nil
+}
+
+func (m *MockService) Waits() []*models.Wait {
+	return nil
+}
+
+func (m *MockService) Visits() []*models.Visit {
+	return nil
+}
+
+func (m *MockService) NotifyEvent(e *models.Event, incoming bool) {}
+
+func (m *MockService) Replay(fn func()) {
+	fn()
+}
+
 func TestFileHandler_ProcessEvents(t *testing.T) {
-	cfg := &config.Config{}
+	cfg := &config.Config{NumberOfTables: 5}
 	time, _ := utils.Parse("10:00")
 	tests := []struct {
 		name     string
@@ -228,6 +283,36 @@ func TestFileHandler_ProcessEvents(t *testing.T) {
 			err:   "10:00 2 diman",
 			mock:  &MockService{},
 		},
+		{
+			name:  "malformed event with table out of range",
+			input: "10:00 2 diman 6\n",
+			err:   "10:00 2 diman 6",
+			mock:  &MockService{},
+		},
+		{
+			name:  "client arrive with trailing fields",
+			input: "10:00 1 diman 2 x\n",
+			expected: []*models.Event{
+				{
+					Code:       models.ClientArrived,
+					Timestamp:  time,
+					ClientName: "diman",
+				},
+			},
+			mock: &MockService{},
+		},
+		{
+			name:  "malformed event with too many fields",
+			input: "10:00 1 diman 2 x y\n",
+			err:   "10:00 1 diman 2 x y",
+			mock:  &MockService{},
+		},
+		{
+			name:  "malformed event with unknown code",
+			input: "10:00 20 diman\n",
+			err:   "10:00 20 diman",
+			mock:  &MockService{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -250,3 +335,557 @@ func TestFileHandler_ProcessEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistry_CustomCode(t *testing.T) {
+	cfg := &config.Config{NumberOfTables: 5}
+	scanner := bufio.NewScanner(strings.NewReader("10:00 20 diman\n"))
+	handler := NewFileHandler(scanner, &MockService{}, cfg)
+	err := handler.Registry.Register(20, &EventType{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Parse: func(cfg *config.Config, e *models.Event, args []string) error {
+			e.ClientName = args[0]
+			return nil
+		},
+		Handle: func(svc Service, e *models.Event) []*models.Event {
+			return []*models.Event{ErrorEvent(e.Timestamp, errors.New("Custom"))}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := handler.Registry.Register(20, &EventType{}); err == nil {
+		t.Error("expected error registering code 20 twice")
+	}
+	if err := handler.ProcessEvents(); err != nil {
+		t.Fatalf("ProcessEvents: %v", err)
+	}
+	if len(handler.ee) != 2 || handler.ee[1].ErrorMsg.Error() != "Custom" {
+		t.Errorf("unexpected events: %v", handler.ee)
+	}
+}
+
+func TestFileHandler_Corrections(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		err     string
+		events  []string
+		revenue []models.Money
+	}{
+		{
+			name:    "amend table",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n09:40 5 2 09:20 2 alice 2\n11:00 4 alice\n",
+			events:  []string{"09:10 1 alice", "09:20 2 alice 2", "09:40 5 2 09:20 2 alice 2", "11:00 4 alice"},
+			revenue: []models.Money{0, 2000},
+		},
+		{
+			name:    "void arrival",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n09:30 5 1 void\n",
+			events:  []string{"09:20 2 alice 1", "09:20 13 ClientUnknown", "09:30 5 1 void"},
+			revenue: []models.Money{0, 0},
+		},
+		{
+			name:    "amend time recomputes billing",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n11:30 4 alice\n11:40 5 3 10:00 4 alice\n",
+			events:  []string{"09:10 1 alice", "09:20 2 alice 1", "10:00 4 alice", "11:40 5 3 10:00 4 alice"},
+			revenue: []models.Money{1000, 0},
+		},
+		{
+			name:  "reference to a later event",
+			input: "09:10 1 alice\n09:20 5 2 void\n",
+			err:   "09:20 5 2 void",
+		},
+		{
+			name:  "correction of a correction",
+			input: "09:10 1 alice\n09:20 5 1 void\n09:30 5 2 void\n",
+			err:   "09:30 5 2 void",
+		},
+		{
+			name:  "amended time out of order",
+			input: "09:10 1 alice\n09:20 2 alice 1\n09:30 5 1 09:25 1 alice\n",
+			err:   "09:30 5 1 09:25 1 alice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 1000}
+			cfg.OpeningTime, _ = utils.Parse("09:00")
+			cfg.ClosingTime, _ = utils.Parse("19:00")
+			svc := service.New(cfg, storage.NewInMemRepo(cfg))
+			h := NewFileHandler(bufio.NewScanner(strings.NewReader(tt.input)), svc, cfg)
+			err := h.ProcessEvents()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error: %s, got: %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			day := h.Report()
+			var events []string
+			for _, e := range h.ee {
+				events = append(events, e.String())
+			}
+			if !reflect.DeepEqual(events, tt.events) {
+				t.Errorf("Expected events: %q, got: %q", tt.events, events)
+			}
+			var revenue []models.Money
+			for _, p := range day.Profits {
+				revenue = append(revenue, p.Sum)
+			}
+			if !reflect.DeepEqual(revenue, tt.revenue) {
+				t.Errorf("Expected revenue: %v, got: %v", tt.revenue, revenue)
+			}
+			if len(day.Corrections) != 1 {
+				t.Errorf("Expected one correction, got %d", len(day.Corrections))
+			}
+		})
+	}
+}
+
+func TestFileHandler_CorrectionReceipts(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		files   map[string]string
+		written int
+	}{
+		{
+			name:    "amended arrival replaces the receipt",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n10:00 4 alice\n10:30 5 1 09:15 1 alice\n",
+			files:   map[string]string{"alice-0915.txt": "Visit: 09:15-10:00"},
+			written: 2,
+		},
+		{
+			name:    "amended departure rewrites the receipt",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n11:30 4 alice\n11:40 5 3 10:00 4 alice\n",
+			files:   map[string]string{"alice-0910.txt": "Visit: 09:10-10:00"},
+			written: 2,
+		},
+		{
+			name:    "voided departure removes the receipt",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n10:00 4 alice\n10:30 5 3 void\n",
+			files:   map[string]string{"alice-0910.txt": "Visit: 09:10-19:00"},
+			written: 2,
+		},
+		{
+			name:    "unchanged visit is not written again",
+			input:   "09:10 1 alice\n09:20 2 alice 1\n10:00 4 alice\n10:10 1 bob\n10:30 5 4 10:20 1 bob\n11:00 4 bob\n",
+			files:   map[string]string{"alice-0910.txt": "Visit: 09:10-10:00", "bob-1020.txt": "Visit: 10:20-11:00"},
+			written: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 1000}
+			cfg.OpeningTime, _ = utils.Parse("09:00")
+			cfg.ClosingTime, _ = utils.Parse("19:00")
+			dir := t.TempDir()
+			written := 0
+			svc := service.New(cfg, storage.NewInMemRepo(cfg))
+			h := NewFileHandler(bufio.NewScanner(strings.NewReader(tt.input)), svc, cfg)
+			h.VisitEnded = func(v *models.Visit) {
+				written++
+				if err := receipt.WriteFile(dir, "", receipt.New(v, cfg)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			h.VisitVoided = func(v *models.Visit) {
+				if err := receipt.RemoveFile(dir, "", receipt.New(v, cfg)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := h.ProcessEvents(); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			h.Report()
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.files) {
+				t.Errorf("Expected receipts %v, got %v", tt.files, entries)
+			}
+			for name, visit := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Errorf("Expected receipt %s: %v", name, err)
+					continue
+				}
+				if !strings.Contains(string(data), visit) {
+					t.Errorf("%s: expected %q in:\n%s", name, visit, data)
+				}
+			}
+			if written != tt.written {
+				t.Errorf("Expected %d receipts written, got %d", tt.written, written)
+			}
+		})
+	}
+}
+
+// TestFileHandler_ReceiptsReconcile checks that the receipts of a day add
+// up to the table revenue and to the total of the Revenue section, with
+// short sessions sharing a table, a zone rate, extras and discounts.
+func TestFileHandler_ReceiptsReconcile(t *testing.T) {
+	input := `09:10 1 alice
+09:20 2 alice 1
+09:25 10 alice cola 50 2
+09:40 4 alice
+09:30 1 bob
+09:45 2 bob 1
+10:05 4 bob
+10:00 1 carol
+10:00 2 carol 2
+11:00 1 dave
+11:01 10 dave tea 30 1
+11:02 2 dave 1
+14:30 4 dave
+`
+	cfg := &config.Config{
+		NumberOfTables: 2,
+		QueueCapacity:  2,
+		HourlyRate:     1000,
+		Zones:          []config.Zone{{Name: "vip", Tables: []int{2}, HourlyRate: 2550}},
+		Discount:       config.Discount{Percent: 10, MinHours: 3},
+	}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader(input)), svc, cfg)
+	var tables, discounts, total models.Money
+	h.VisitEnded = func(v *models.Visit) {
+		r := receipt.New(v, cfg)
+		tables += r.Tables
+		for _, d := range r.Discounts {
+			discounts += d.Amount
+		}
+		total += r.Total
+	}
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	day := h.Report()
+
+	var profits models.Money
+	for _, p := range svc.CalcProfits() {
+		profits += p.Sum
+	}
+	if tables != profits {
+		t.Errorf("Expected receipts to charge %s for tables, got %s", profits, tables)
+	}
+	if day.Revenue == nil {
+		t.Fatal("Expected a Revenue section")
+	}
+	if discounts == 0 || discounts != day.Revenue.Discounts {
+		t.Errorf("Expected receipt discounts %s to match the report's %s", discounts, day.Revenue.Discounts)
+	}
+	if total != day.Revenue.Total {
+		t.Errorf("Expected receipts to total %s, got %s", day.Revenue.Total, total)
+	}
+}
+
+func TestFileHandler_OutOfService(t *testing.T) {
+	input := `09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:35 2 bob 2
+09:40 1 carol
+09:41 3 carol
+10:00 6 1
+10:05 2 carol 1
+10:10 6 1
+11:00 4 bob
+12:00 7 1
+12:30 6 2
+12:40 1 dave
+12:41 3 dave
+13:00 7 2
+13:05 4 alice
+13:10 4 dave
+13:20 6 2
+`
+	expected := []string{
+		"09:10 1 alice", "09:20 2 alice 1", "09:30 1 bob", "09:35 2 bob 2", "09:40 1 carol", "09:41 3 carol",
+		"10:00 6 1", "10:00 15 alice",
+		"10:05 2 carol 1", "10:05 13 OutOfService",
+		"10:10 6 1", "10:10 13 OutOfService",
+		"11:00 4 bob", "11:00 2 carol 2",
+		"12:00 7 1", "12:00 12 alice 1",
+		"12:30 6 2", "12:30 15 carol",
+		"12:40 1 dave", "12:41 3 dave",
+		"13:00 7 2", "13:00 12 carol 2",
+		"13:05 4 alice", "13:05 2 dave 1",
+		"13:10 4 dave",
+		"13:20 6 2", "13:20 12 carol 1",
+	}
+	cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 1000}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader(input)), svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var events []string
+	for _, e := range h.ee {
+		events = append(events, e.String())
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events: %q, got: %q", expected, events)
+	}
+
+	day := h.Report()
+	downtime := []time.Duration{2 * time.Hour, 6*time.Hour + 10*time.Minute}
+	for i, p := range day.Profits {
+		if p.Table.Downtime != downtime[i] {
+			t.Errorf("table %d: expected downtime %s, got %s", p.Table.Id, downtime[i], p.Table.Downtime)
+		}
+	}
+}
+
+func TestFileHandler_MoveTable(t *testing.T) {
+	input := `09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:35 2 bob 2
+10:00 2 alice 2
+10:30 2 alice 1
+11:00 4 bob
+11:30 2 alice 2
+12:00 4 alice
+`
+	expected := []string{
+		"09:10 1 alice", "09:20 2 alice 1", "09:30 1 bob", "09:35 2 bob 2",
+		"10:00 2 alice 2", "10:00 13 PlaceIsBusy",
+		"10:30 2 alice 1", "10:30 13 AlreadyAtTable",
+		"11:00 4 bob", "11:30 2 alice 2", "12:00 4 alice",
+	}
+	cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 10}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader(input)), svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var events []string
+	for _, e := range h.ee {
+		events = append(events, e.String())
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events: %q, got: %q", expected, events)
+	}
+
+	day := h.Report()
+	var alice []string
+	for _, s := range day.Sessions {
+		if s.ClientName == "alice" {
+			alice = append(alice, fmt.Sprintf("%d %s-%s", s.TableID, utils.Format(s.Start), utils.Format(s.End)))
+		}
+	}
+	want := []string{"1 09:20-11:30", "2 11:30-12:00"}
+	if !reflect.DeepEqual(alice, want) {
+		t.Errorf("expected alice's sessions %q, got %q", want, alice)
+	}
+	sums := []models.Money{30, 20}
+	for i, p := range day.Profits {
+		if p.Sum != sums[i] {
+			t.Errorf("table %d: expected %s, got %s", p.Table.Id, sums[i], p.Sum)
+		}
+	}
+}
+
+func TestFileHandler_Pause(t *testing.T) {
+	input := `09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:31 3 bob
+10:00 8 alice
+10:20 9 alice
+10:30 8 alice
+10:30 8 alice
+11:00 9 bob
+12:00 4 bob
+`
+	expected := []string{
+		"09:10 1 alice", "09:20 2 alice 1", "09:30 1 bob", "09:31 3 bob",
+		"10:00 8 alice", "10:20 9 alice", "10:30 8 alice",
+		"10:30 8 alice", "10:30 13 AlreadyPaused",
+		"11:00 9 bob", "11:00 13 NotSeated",
+		"11:15 14 alice 1", "11:15 12 bob 1",
+		"12:00 4 bob",
+	}
+	cfg := &config.Config{NumberOfTables: 1, QueueCapacity: 1, HourlyRate: 10, MaxPause: 45 * time.Minute}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader(input)), svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var events []string
+	for _, e := range h.ee {
+		events = append(events, e.String())
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events: %q, got: %q", expected, events)
+	}
+
+	day := h.Report()
+	if len(day.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(day.Sessions))
+	}
+	if d := day.Sessions[0].Duration(); d != 50*time.Minute {
+		t.Errorf("expected alice to be billed for 50m, got %s", d)
+	}
+	if total := day.Profits[0].Table.TotalTime; total != 95*time.Minute {
+		t.Errorf("expected table time 1h35m, got %s", total)
+	}
+}
+
+func TestFileHandler_CheckAtClosing(t *testing.T) {
+	input := `09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:31 3 bob
+10:00 8 alice
+`
+	cfg := &config.Config{NumberOfTables: 1, QueueCapacity: 1, HourlyRate: 10, MaxPause: 30 * time.Minute}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader(input)), svc, cfg)
+	var out strings.Builder
+	h.Out = &out
+	var checked []string
+	broken := errors.New("broken at closing")
+	h.Check = func(e *models.Event) error {
+		checked = append(checked, e.String())
+		if e.Code == models.ClientForceLeft {
+			return broken
+		}
+		return nil
+	}
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := h.EndDay(); err != broken {
+		t.Fatalf("Expected the closing check to fail the day, got: %v", err)
+	}
+	expected := []string{
+		"09:10 1 alice", "09:20 2 alice 1", "09:30 1 bob", "09:31 3 bob", "10:00 8 alice",
+		"10:30 12 bob 1", "19:00 11 bob",
+	}
+	if !reflect.DeepEqual(checked, expected) {
+		t.Errorf("Expected checks after %q, got %q", expected, checked)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no report, got:\n%s", out.String())
+	}
+}
+
+func TestFileHandler_ExtendedTime(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{NumberOfTables: 1, QueueCapacity: 1, HourlyRate: 1000,
+		Time: utils.TimeFormat{Extended: true}}
+	cfg.OpeningTime, _ = cfg.Time.Parse("09:00:00")
+	cfg.ClosingTime, _ = cfg.Time.Parse("19:00:00")
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader("")), svc, cfg)
+	for _, line := range []string{"09:10:05 1 alice", "09:10:30 2 alice 1", "10:10:31 4 alice"} {
+		if err := h.ProcessLine(line); err != nil {
+			t.Fatalf("unexpected error on %q", line)
+		}
+	}
+	if err := h.ProcessLine("2026-10-19T10:20:00+03:00 1 bob"); err == nil {
+		t.Error("expected a dated event to be rejected in a day without a date")
+	}
+
+	day := h.Report()
+	if got := day.Events[2].Format(cfg.Time); got != "10:10:31 4 alice" {
+		t.Errorf("unexpected event %q", got)
+	}
+	// One hour and one second is billed as two hours.
+	if got := day.Profits[0].Format(cfg.Time); got != "1 20 01:00:01" {
+		t.Errorf("unexpected profit %q", got)
+	}
+}
+
+func TestFileHandler_DaylightSaving(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		input   string
+		played  []time.Duration
+		revenue []models.Money
+	}{
+		{
+			// 02:00 CET becomes 03:00 CEST, so 01:30-03:30 lasts one hour.
+			name: "spring forward",
+			date: "2026-03-29",
+			input: `01:30 1 alice
+01:30 2 alice 1
+03:30 4 alice
+`,
+			played:  []time.Duration{time.Hour},
+			revenue: []models.Money{1000, 0},
+		},
+		{
+			// 03:00 CEST becomes 02:00 CET, so 01:30-03:30 lasts three hours
+			// and bob's 02:10 comes after alice's 02:40.
+			name: "fall back",
+			date: "2026-10-25",
+			input: `01:30 1 alice
+01:30 2 alice 1
+02:40 1 bob
+02:10 2 bob 2
+03:30 4 alice
+03:30 4 bob
+`,
+			played:  []time.Duration{3 * time.Hour, 80 * time.Minute},
+			revenue: []models.Money{3000, 2000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "club.json")
+			json := `{"timezone": "Europe/Berlin", "date": "` + tt.date + `"}`
+			if err := os.WriteFile(path, []byte(json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			input := "2\n00:00 06:00\n10\n" + tt.input
+			cfg, err := config.Load(bufio.NewReader(strings.NewReader(input)), path, utils.TimeFormat{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc := service.New(cfg, storage.NewInMemRepo(cfg))
+			h := NewFileHandler(cfg.FileScanner, svc, cfg)
+			if err := h.ProcessEvents(); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			day := h.Report()
+			if len(day.Sessions) != len(tt.played) {
+				t.Fatalf("expected %d sessions, got %d", len(tt.played), len(day.Sessions))
+			}
+			for i, s := range day.Sessions {
+				if s.Duration() != tt.played[i] {
+					t.Errorf("session %d: expected %s, got %s", i, tt.played[i], s.Duration())
+				}
+			}
+			var revenue []models.Money
+			for _, p := range day.Profits {
+				revenue = append(revenue, p.Sum)
+			}
+			if !reflect.DeepEqual(revenue, tt.revenue) {
+				t.Errorf("Expected revenue: %v, got: %v", tt.revenue, revenue)
+			}
+			if got := day.Events[len(day.Events)-1].String(); !strings.HasPrefix(got, "03:30 4") {
+				t.Errorf("expected wall-clock times in the output, got %q", got)
+			}
+		})
+	}
+}
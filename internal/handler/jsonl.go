@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	InputAuto  = "auto"
+	InputText  = "text"
+	InputJSONL = "jsonl"
+)
+
+func ValidInput(input string) bool {
+	switch input {
+	case "", InputAuto, InputText, InputJSONL:
+		return true
+	}
+	return false
+}
+
+// jsonLine is an event written as one JSON object per line, for example
+// {"time": "09:10", "code": 2, "client": "alice", "table": 1}. It is turned
+// into the fields of a text line, so both formats are validated alike.
+type jsonLine struct {
+	Time     string      `json:"time"`
+	Code     int         `json:"code"`
+	Client   string      `json:"client"`
+	Table    int         `json:"table"`
+	Item     string      `json:"item"`
+	Price    json.Number `json:"price"`
+	Quantity int         `json:"quantity"`
+
+	// Ref is the sequence number of the event a correction voids with
+	// Void or replaces with Amend.
+	Ref   int       `json:"ref"`
+	Void  bool      `json:"void"`
+	Amend *jsonLine `json:"amend"`
+
+	// Args are passed after the fields above, for custom event codes.
+	Args []string `json:"args"`
+}
+
+func isJSONLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "{")
+}
+
+func parseJSONLine(line string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.DisallowUnknownFields()
+	var l jsonLine
+	if err := dec.Decode(&l); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errInvalidField
+	}
+	return l.fields(), nil
+}
+
+func (l *jsonLine) fields() []string {
+	fields := []string{l.Time, strconv.Itoa(l.Code)}
+	if l.Ref != 0 {
+		fields = append(fields, strconv.Itoa(l.Ref))
+		if l.Void {
+			fields = append(fields, "void")
+		}
+		if l.Amend != nil {
+			fields = append(fields, l.Amend.fields()...)
+		}
+	}
+	if l.Client != "" {
+		fields = append(fields, l.Client)
+	}
+	if l.Item != "" {
+		fields = append(fields, l.Item, l.Price.String(), strconv.Itoa(l.Quantity))
+	}
+	if l.Table != 0 {
+		fields = append(fields, strconv.Itoa(l.Table))
+	}
+	return append(fields, l.Args...)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func TestFileHandler_JSONLines(t *testing.T) {
+	text := []string{
+		"09:10 1 alice",
+		"09:20 2 alice 2",
+		"09:30 10 alice cola 2.50 2",
+		"09:40 1 bob",
+		"09:45 5 4 void",
+		"09:50 6 1",
+		"10:00 5 1 09:05 1 alice",
+		"10:10 4 alice",
+	}
+	jsonl := []string{
+		`{"time": "09:10", "code": 1, "client": "alice"}`,
+		`{"time": "09:20", "code": 2, "client": "alice", "table": 2}`,
+		`{"time": "09:30", "code": 10, "client": "alice", "item": "cola", "price": 2.5, "quantity": 2}`,
+		`{"time": "09:40", "code": 1, "client": "bob"}`,
+		`{"time": "09:45", "code": 5, "ref": 4, "void": true}`,
+		`{"time": "09:50", "code": 6, "table": 1}`,
+		`{"time": "10:00", "code": 5, "ref": 1, "amend": {"time": "09:05", "code": 1, "client": "alice"}}`,
+		`{"time": "10:10", "code": 4, "client": "alice"}`,
+	}
+	run := func(input string, lines []string) []string {
+		cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 1000}
+		cfg.OpeningTime, _ = utils.Parse("09:00")
+		cfg.ClosingTime, _ = utils.Parse("19:00")
+		h := NewFileHandler(bufio.NewScanner(strings.NewReader("")), service.New(cfg, storage.NewInMemRepo(cfg)), cfg)
+		h.Input = input
+		for _, line := range lines {
+			if err := h.ProcessLine(line); err != nil {
+				t.Fatalf("unexpected error on %q", line)
+			}
+		}
+		var events []string
+		for _, e := range h.ee {
+			events = append(events, e.String())
+		}
+		return events
+	}
+
+	want := run(InputText, text)
+	if got := run(InputJSONL, jsonl); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events: %q, got: %q", want, got)
+	}
+	mixed := append(append([]string{}, jsonl[:4]...), "# comments are skipped")
+	mixed = append(mixed, text[4:]...)
+	if got := run(InputAuto, mixed); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events: %q, got: %q", want, got)
+	}
+}
+
+func TestFileHandler_JSONLinesInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"bad client name", `{"time": "09:10", "code": 1, "client": "Alice!"}`},
+		{"table out of range", `{"time": "09:10", "code": 2, "client": "alice", "table": 3}`},
+		{"unknown code", `{"time": "09:10", "code": 20, "client": "alice"}`},
+		{"missing client", `{"time": "09:10", "code": 1}`},
+		{"unknown field", `{"time": "09:10", "code": 1, "client": "alice", "name": "bob"}`},
+		{"bad time", `{"time": "9.10", "code": 1, "client": "alice"}`},
+		{"not an object", `{"time": "09:10", "code": 1, "client": "alice"`},
+	}
+	cfg := &config.Config{NumberOfTables: 2, QueueCapacity: 2, HourlyRate: 1000}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewFileHandler(bufio.NewScanner(strings.NewReader("")), &MockService{}, cfg)
+			if err := h.ProcessLine(tt.line); err == nil || err.Error() != tt.line {
+				t.Errorf("expected the line as an error, got %v", err)
+			}
+		})
+	}
+
+	h := NewFileHandler(bufio.NewScanner(strings.NewReader("")), &MockService{}, cfg)
+	h.Input = InputJSONL
+	if err := h.ProcessLine("09:10 1 alice"); err == nil {
+		t.Error("expected a text line to be rejected in jsonl input")
+	}
+}
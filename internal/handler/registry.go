@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+)
+
+var errInvalidField = errors.New("invalid field")
+
+// EventType describes an incoming event code. MinArgs and MaxArgs bound the
+// number of fields after the code. Parse validates those fields and fills
+// the event; Handle applies it and returns the outgoing events it caused.
+type EventType struct {
+	MinArgs int
+	MaxArgs int
+	Parse   func(cfg *config.Config, e *models.Event, args []string) error
+	Handle  func(svc Service, e *models.Event) []*models.Event
+}
+
+type Registry struct {
+	types map[int]*EventType
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		types: make(map[int]*EventType),
+	}
+}
+
+// legacyMaxArgs keeps the original codes 1-4 lenient: a line of up to five
+// fields is accepted and the fields after the known ones are ignored.
+const legacyMaxArgs = 3
+
+// DefaultRegistry returns a registry with the incoming events of the club.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.MustRegister(models.ClientArrived, &EventType{
+		MinArgs: 1,
+		MaxArgs: legacyMaxArgs,
+		Parse:   parseClientEvent,
+		Handle:  handleClientArrived,
+	})
+	r.MustRegister(models.ClientSat, &EventType{
+		MinArgs: 2,
+		MaxArgs: legacyMaxArgs,
+		Parse:   parseTableEvent,
+		Handle:  handleClientSat,
+	})
+	r.MustRegister(models.ClientWaiting, &EventType{
+		MinArgs: 1,
+		MaxArgs: legacyMaxArgs,
+		Parse:   parseClientEvent,
+		Handle:  handleClientWaiting,
+	})
+	r.MustRegister(models.ClientLeft, &EventType{
+		MinArgs: 1,
+		MaxArgs: legacyMaxArgs,
+		Parse:   parseClientEvent,
+		Handle:  handleClientLeft,
+	})
+	r.MustRegister(models.TableOutOfService, &EventType{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Parse:   parseTableOnlyEvent,
+		Handle:  handleTableOutOfService,
+	})
+	r.MustRegister(models.TableInService, &EventType{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Parse:   parseTableOnlyEvent,
+		Handle:  handleTableInService,
+	})
+	r.MustRegister(models.ClientPaused, &EventType{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Parse:   parseClientEvent,
+		Handle:  handleClientPaused,
+	})
+	r.MustRegister(models.ClientResumed, &EventType{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Parse:   parseClientEvent,
+		Handle:  handleClientResumed,
+	})
+	r.MustRegister(models.ClientOrdered, &EventType{
+		MinArgs: 4,
+		MaxArgs: 4,
+		Parse:   parseOrderEvent,
+		Handle:  handleClientOrdered,
+	})
+	return r
+}
+
+func (r *Registry) Register(code int, t *EventType) error {
+	if _, exists := r.types[code]; exists {
+		return fmt.Errorf("event code %d already registered", code)
+	}
+	if t.Parse == nil || t.Handle == nil || t.MinArgs < 0 || t.MaxArgs < t.MinArgs {
+		return fmt.Errorf("event code %d: incomplete event type", code)
+	}
+	r.types[code] = t
+	return nil
+}
+
+func (r *Registry) MustRegister(code int, t *EventType) {
+	if err := r.Register(code, t); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Lookup(code int) (*EventType, bool) {
+	t, ok := r.types[code]
+	return t, ok
+}
+
+// ParseClient validates a client name field.
+func ParseClient(s string) (string, error) {
+	if !models.ValidClientName.MatchString(s) {
+		return "", errInvalidField
+	}
+	return s, nil
+}
+
+// ParseTable validates a table number field against the configuration.
+func ParseTable(cfg *config.Config, s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 || id > cfg.NumberOfTables {
+		return 0, errInvalidField
+	}
+	return id, nil
+}
+
+func ErrorEvent(timestamp time.Time, err error) *models.Event {
+	return &models.Event{
+		Code:      models.EventError,
+		Timestamp: timestamp,
+		ErrorMsg:  err,
+	}
+}
+
+func parseClientEvent(cfg *config.Config, e *models.Event, args []string) error {
+	name, err := ParseClient(args[0])
+	if err != nil {
+		return err
+	}
+	e.ClientName = name
+	return nil
+}
+
+func parseTableEvent(cfg *config.Config, e *models.Event, args []string) error {
+	if err := parseClientEvent(cfg, e, args); err != nil {
+		return err
+	}
+	tableID, err := ParseTable(cfg, args[1])
+	if err != nil {
+		return err
+	}
+	e.TableID = tableID
+	return nil
+}
+
+func parseTableOnlyEvent(cfg *config.Config, e *models.Event, args []string) error {
+	tableID, err := ParseTable(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	e.TableID = tableID
+	return nil
+}
+
+// parseOrderEvent parses "<client> <item> <price> <quantity>".
+func parseOrderEvent(cfg *config.Config, e *models.Event, args []string) error {
+	if err := parseClientEvent(cfg, e, args); err != nil {
+		return err
+	}
+	if !models.ValidItemName.MatchString(args[1]) {
+		return errInvalidField
+	}
+	price, err := models.ParseMoney(args[2])
+	if err != nil {
+		return errInvalidField
+	}
+	quantity, err := strconv.Atoi(args[3])
+	if err != nil || quantity < 1 {
+		return errInvalidField
+	}
+	e.Extra = &models.Extra{
+		ClientName: e.ClientName,
+		Item:       args[1],
+		Price:      price,
+		Quantity:   quantity,
+		Timestamp:  e.Timestamp,
+	}
+	return nil
+}
+
+func handleClientArrived(svc Service, e *models.Event) []*models.Event {
+	if err := svc.ClientArrive(e.Timestamp, e.ClientName); err != nil {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	return nil
+}
+
+func handleClientSat(svc Service, e *models.Event) []*models.Event {
+	if err := svc.ClientSit(e.Timestamp, e.ClientName, e.TableID); err != nil {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	return nil
+}
+
+func handleClientWaiting(svc Service, e *models.Event) []*models.Event {
+	err := svc.ClientWait(e.Timestamp, e.ClientName)
+	if errors.Is(err, service.ErrICanWaitNoLonger) {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	if errors.Is(err, service.ErrQueueFull) {
+		return []*models.Event{{
+			Code:       models.ClientForceLeft,
+			Timestamp:  e.Timestamp,
+			ClientName: e.ClientName,
+		}}
+	}
+	return nil
+}
+
+func handleClientLeft(svc Service, e *models.Event) []*models.Event {
+	var out []*models.Event
+	dequeued, tableID, err := svc.ClientLeave(e.Timestamp, e.ClientName)
+	if err != nil && errors.Is(err, service.ErrClientUnknown) {
+		out = append(out, ErrorEvent(e.Timestamp, err))
+	}
+	if dequeued != nil {
+		out = append(out, &models.Event{
+			Code:       models.ClientSat,
+			Timestamp:  e.Timestamp,
+			ClientName: dequeued.Name,
+			TableID:    tableID,
+		})
+	}
+	return out
+}
+
+func handleTableOutOfService(svc Service, e *models.Event) []*models.Event {
+	client, moved, err := svc.TableOutOfService(e.Timestamp, e.TableID)
+	switch {
+	case errors.Is(err, service.ErrOutOfService):
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	case client == nil:
+		return nil
+	case errors.Is(err, service.ErrQueueFull):
+		return []*models.Event{{
+			Code:       models.ClientForceLeft,
+			Timestamp:  e.Timestamp,
+			ClientName: client.Name,
+		}}
+	case moved != 0:
+		return []*models.Event{{
+			Code:       models.ClientSatFromQueue,
+			Timestamp:  e.Timestamp,
+			ClientName: client.Name,
+			TableID:    moved,
+		}}
+	}
+	return []*models.Event{{
+		Code:       models.ClientQueued,
+		Timestamp:  e.Timestamp,
+		ClientName: client.Name,
+	}}
+}
+
+func handleTableInService(svc Service, e *models.Event) []*models.Event {
+	dequeued, _ := svc.TableInService(e.Timestamp, e.TableID)
+	if dequeued == nil {
+		return nil
+	}
+	return []*models.Event{{
+		Code:       models.ClientSatFromQueue,
+		Timestamp:  e.Timestamp,
+		ClientName: dequeued.Name,
+		TableID:    e.TableID,
+	}}
+}
+
+func handleClientPaused(svc Service, e *models.Event) []*models.Event {
+	if err := svc.ClientPause(e.Timestamp, e.ClientName); err != nil {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	return nil
+}
+
+func handleClientResumed(svc Service, e *models.Event) []*models.Event {
+	if err := svc.ClientResume(e.Timestamp, e.ClientName); err != nil {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	return nil
+}
+
+func handleClientOrdered(svc Service, e *models.Event) []*models.Event {
+	// The tab keeps its own copy so that a replay after a correction does
+	// not see the table set by an earlier run.
+	extra := *e.Extra
+	if err := svc.ClientOrder(&extra); err != nil {
+		return []*models.Event{ErrorEvent(e.Timestamp, err)}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// BillableHours rounds time spent at a table up to whole hours.
+func BillableHours(d time.Duration) int {
+	hours := int(d / time.Hour)
+	if d%time.Hour != 0 {
+		hours++
+	}
+	return hours
+}
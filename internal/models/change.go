@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const (
+	TableOccupied = iota + 1
+	TableFreed
+	QueueJoined
+	QueueLeft
+	TableDisabled
+	TableEnabled
+	SessionPaused
+	SessionResumed
+)
+
+// Change is a state change of the club: a table taken or released, or a
+// client joining or leaving the queue.
+type Change struct {
+	Kind       int
+	Timestamp  time.Time
+	ClientName string
+	TableID    int
+}
+
+func ChangeName(kind int) string {
+	switch kind {
+	case TableOccupied:
+		return "table_occupied"
+	case TableFreed:
+		return "table_freed"
+	case QueueJoined:
+		return "queue_joined"
+	case QueueLeft:
+		return "queue_left"
+	case TableDisabled:
+		return "table_disabled"
+	case TableEnabled:
+		return "table_enabled"
+	case SessionPaused:
+		return "session_paused"
+	case SessionResumed:
+		return "session_resumed"
+	}
+	return "unknown"
+}
+
+func (c *Change) String() string {
+	return c.Format(utils.TimeFormat{})
+}
+
+// Format writes the change with its timestamp in the format f.
+func (c *Change) Format(f utils.TimeFormat) string {
+	s := f.Format(c.Timestamp) + " " + ChangeName(c.Kind)
+	if c.ClientName != "" {
+		s += " " + c.ClientName
+	}
+	if c.TableID != 0 {
+		s += fmt.Sprintf(" %d", c.TableID)
+	}
+	return s
+}
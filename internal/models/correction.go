@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Correction records a voided or amended event. Amended is nil when the
+// event was voided.
+type Correction struct {
+	Seq       int
+	Ref       int
+	Timestamp time.Time
+	Original  *Event
+	Amended   *Event
+}
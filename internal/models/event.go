@@ -10,14 +10,34 @@ import (
 
 var ErrInvalidEvent = errors.New("invalid event")
 
+// Incoming event codes.
 const (
-	ClientArrived = iota + 1
-	ClientSat
-	ClientWaiting
-	ClientLeft
-	ClientForceLeft = iota + 7
-	ClientSatFromQueue
-	EventError
+	ClientArrived = 1
+	ClientSat     = 2
+	ClientWaiting = 3
+	ClientLeft    = 4
+	// EventCorrection voids or amends an earlier incoming event referred to
+	// by its sequence number.
+	EventCorrection   = 5
+	TableOutOfService = 6
+	TableInService    = 7
+	ClientPaused      = 8
+	ClientResumed     = 9
+	// ClientOrdered adds an item to a client's tab.
+	ClientOrdered = 10
+)
+
+// Outgoing event codes. Incoming codes are never generated as outgoing
+// events.
+const (
+	ClientForceLeft    = 11
+	ClientSatFromQueue = 12
+	EventError         = 13
+	// TableReleased is a table given up after the maximum pause.
+	TableReleased = 14
+	// ClientQueued is a client put in the queue because their table went
+	// out of service and no other table was free.
+	ClientQueued = 15
 )
 
 type Event struct {
@@ -26,15 +46,37 @@ type Event struct {
 	ClientName string
 	TableID    int
 	ErrorMsg   error
+
+	// Ref and Amend are only set for EventCorrection.
+	Ref   int
+	Amend *Event
+
+	// Extra is only set for ClientOrdered.
+	Extra *Extra
 }
 
 func (e *Event) String() string {
+	return e.Format(utils.TimeFormat{})
+}
+
+// Format writes the event as a log line with its timestamp in the format f.
+func (e *Event) Format(f utils.TimeFormat) string {
 	switch e.Code {
-	case ClientSatFromQueue, ClientSat:
-		return fmt.Sprintf("%s %d %s %d", utils.Format(e.Timestamp), e.Code, e.ClientName, e.TableID)
+	case ClientSatFromQueue, ClientSat, TableReleased:
+		return fmt.Sprintf("%s %d %s %d", f.Format(e.Timestamp), e.Code, e.ClientName, e.TableID)
 	case EventError:
-		return fmt.Sprintf("%s %d %s", utils.Format(e.Timestamp), e.Code, e.ErrorMsg.Error())
+		return fmt.Sprintf("%s %d %s", f.Format(e.Timestamp), e.Code, e.ErrorMsg.Error())
+	case TableOutOfService, TableInService:
+		return fmt.Sprintf("%s %d %d", f.Format(e.Timestamp), e.Code, e.TableID)
+	case ClientOrdered:
+		return fmt.Sprintf("%s %d %s %s %s %d", f.Format(e.Timestamp), e.Code, e.ClientName,
+			e.Extra.Item, e.Extra.Price, e.Extra.Quantity)
+	case EventCorrection:
+		if e.Amend == nil {
+			return fmt.Sprintf("%s %d %d void", f.Format(e.Timestamp), e.Code, e.Ref)
+		}
+		return fmt.Sprintf("%s %d %d %s", f.Format(e.Timestamp), e.Code, e.Ref, e.Amend.Format(f))
 	default:
-		return fmt.Sprintf("%s %d %s", utils.Format(e.Timestamp), e.Code, e.ClientName)
+		return fmt.Sprintf("%s %d %s", f.Format(e.Timestamp), e.Code, e.ClientName)
 	}
 }
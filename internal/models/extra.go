@@ -0,0 +1,23 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+var ValidItemName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Extra is an item sold to a client. TableID is the table the client sat at
+// when ordering, 0 if they were not seated.
+type Extra struct {
+	ClientName string
+	Item       string
+	Price      Money
+	Quantity   int
+	TableID    int
+	Timestamp  time.Time
+}
+
+func (e *Extra) Sum() Money {
+	return e.Price.Times(e.Quantity)
+}
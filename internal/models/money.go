@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an amount in minor currency units, such as kopecks. Every
+// currency is assumed to have two minor digits.
+type Money int64
+
+// ParseMoney parses a non-negative amount in major units with at most two
+// decimals, such as "149.50" or "10".
+func ParseMoney(s string) (Money, error) {
+	v, err := parseFixed(s, 2)
+	return Money(v), err
+}
+
+// String writes whole amounts without decimals, so legacy integer rates
+// print as before, and other amounts with two decimals.
+func (m Money) String() string {
+	if m%100 == 0 {
+		return strconv.FormatInt(int64(m/100), 10)
+	}
+	return m.Decimal()
+}
+
+// Decimal writes the amount with two decimals.
+func (m Money) Decimal() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+func (m Money) Times(n int) Money {
+	return m * Money(n)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string in major units.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	v, err := ParseMoney(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Percent is a rate in hundredths of a percent, so 20% is 2000.
+type Percent int64
+
+func ParsePercent(s string) (Percent, error) {
+	v, err := parseFixed(s, 2)
+	return Percent(v), err
+}
+
+func (p Percent) String() string {
+	s := Money(p).String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+	}
+	return s
+}
+
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Percent) UnmarshalJSON(data []byte) error {
+	v, err := ParsePercent(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// parseFixed parses a non-negative decimal number into an integer scaled by
+// 10^digits, rejecting numbers with more decimals.
+func parseFixed(s string, digits int) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || (hasFrac && (frac == "" || len(frac) > digits)) {
+		return 0, ErrInvalidAmount
+	}
+	for _, part := range []string{whole, frac} {
+		if strings.Trim(part, "0123456789") != "" {
+			return 0, ErrInvalidAmount
+		}
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	return v, nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		text    string
+		wantErr bool
+	}{
+		{input: "10", want: 1000, text: "10"},
+		{input: "149.50", want: 14950, text: "149.50"},
+		{input: "0.5", want: 50, text: "0.50"},
+		{input: "0.05", want: 5, text: "0.05"},
+		{input: "10.505", wantErr: true},
+		{input: "-10", wantErr: true},
+		{input: "1.", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "1e3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || got.String() != tt.text {
+				t.Errorf("expected %d (%s), got %d (%s)", tt.want, tt.text, got, got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var v struct {
+		A Money
+		B Money
+	}
+	if err := json.Unmarshal([]byte(`{"A": 149.5, "B": "20"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 14950 || v.B != 2000 {
+		t.Errorf("unexpected amounts %d and %d", v.A, v.B)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"A":149.50,"B":20}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+}
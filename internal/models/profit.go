@@ -8,9 +8,14 @@ import (
 
 type Profit struct {
 	Table *Table
-	Sum   int
+	Sum   Money
 }
 
 func (p *Profit) String() string {
-	return fmt.Sprintf("%d %d %s", p.Table.Id, p.Sum, utils.Format(p.Table.TotalTime))
+	return p.Format(utils.TimeFormat{})
+}
+
+// Format writes the profit line with the time played in the format f.
+func (p *Profit) Format(f utils.TimeFormat) string {
+	return fmt.Sprintf("%d %s %s", p.Table.Id, p.Sum, f.FormatDuration(p.Table.TotalTime))
 }
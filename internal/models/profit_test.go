@@ -3,8 +3,6 @@ package models
 import (
 	"testing"
 	"time"
-
-	"github.com/Korpenter/club/internal/utils"
 )
 
 func TestProfitString(t *testing.T) {
@@ -12,7 +10,7 @@ func TestProfitString(t *testing.T) {
 		Id:        1,
 		Client:    &Client{Name: "pippa"},
 		ClientSat: time.Now(),
-		TotalTime: time.Now().Add(1 * time.Hour),
+		TotalTime: 26*time.Hour + 5*time.Minute,
 	}
 
 	tests := []struct {
@@ -22,8 +20,8 @@ func TestProfitString(t *testing.T) {
 	}{
 		{
 			"BasicProfitTest",
-			Profit{Table: table, Sum: 100},
-			"1 100 " + utils.Format(table.TotalTime),
+			Profit{Table: table, Sum: 10000},
+			"1 100 26:05",
 		},
 	}
 
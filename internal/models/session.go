@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	WaitSeated = iota + 1
+	WaitLeft
+	WaitClosed
+)
+
+// Session is one stay of a client at a table. Hours is what the session
+// adds to the billable hours of its table, so the hours of a table's
+// sessions sum up to what the table is paid for.
+type Session struct {
+	TableID    int
+	ClientName string
+	Start      time.Time
+	End        time.Time
+	Pauses     []*Pause
+	Hours      int
+}
+
+type Pause struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration is the time played at the table, pauses excluded.
+func (s *Session) Duration() time.Duration {
+	return s.End.Sub(s.Start) - s.Paused()
+}
+
+func (s *Session) Paused() time.Duration {
+	var paused time.Duration
+	for _, p := range s.Pauses {
+		paused += p.End.Sub(p.Start)
+	}
+	return paused
+}
+
+// PlayedAt returns the moment the client goes on playing past d of play,
+// so a pause starting exactly at d is skipped as well.
+func (s *Session) PlayedAt(d time.Duration) time.Time {
+	at := s.Start.Add(d)
+	for _, p := range s.Pauses {
+		if p.Start.After(at) {
+			break
+		}
+		at = at.Add(p.End.Sub(p.Start))
+	}
+	return at
+}
+
+type Wait struct {
+	ClientName string
+	Start      time.Time
+	End        time.Time
+	Outcome    int
+}
+
+func (w *Wait) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+type Visit struct {
+	ClientName string
+	Arrived    time.Time
+	Left       time.Time
+	Forced     bool
+	Sessions   []*Session
+	Waits      []*Wait
+	Extras     []*Extra
+}
+
+// Hours is the number of hours the visit is billed for.
+func (v *Visit) Hours() int {
+	var hours int
+	for _, s := range v.Sessions {
+		hours += s.Hours
+	}
+	return hours
+}
+
+// Tab is the sum of the extras ordered during the visit. It is settled when
+// the visit ends.
+func (v *Visit) Tab() Money {
+	var sum Money
+	for _, e := range v.Extras {
+		sum += e.Sum()
+	}
+	return sum
+}
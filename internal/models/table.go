@@ -8,5 +8,15 @@ type Table struct {
 	Id        int
 	Client    *Client
 	ClientSat time.Time
-	TotalTime time.Time
+	// TotalTime is the time played at the table over the day.
+	TotalTime time.Duration
+
+	// PausedAt is set while the session at the table is paused, Pauses
+	// holds the finished pauses of the session.
+	PausedAt time.Time
+	Pauses   []*Pause
+
+	OutOfService bool
+	OutSince     time.Time
+	Downtime     time.Duration
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+)
+
+const (
+	RoundHalfUp = iota
+	RoundHalfEven
+	RoundDown
+	RoundUp
+)
+
+var roundingNames = []string{"half_up", "half_even", "down", "up"}
+
+func ParseRounding(s string) (int, error) {
+	for mode, name := range roundingNames {
+		if s == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q", s)
+}
+
+func RoundingName(mode int) string {
+	return roundingNames[mode]
+}
+
+// Tax describes the sales tax. With Included the prices already contain
+// the tax, otherwise the tax is added on top of them. The tax amount is
+// rounded to a minor unit with Rounding.
+type Tax struct {
+	Rate     Percent
+	Included bool
+	Rounding int
+}
+
+type TaxSplit struct {
+	Net   Money
+	Tax   Money
+	Gross Money
+}
+
+func (t Tax) Split(amount Money) TaxSplit {
+	if t.Included {
+		tax := Money(divRound(int64(amount)*int64(t.Rate), 10000+int64(t.Rate), t.Rounding))
+		return TaxSplit{Net: amount - tax, Tax: tax, Gross: amount}
+	}
+	tax := Money(divRound(int64(amount)*int64(t.Rate), 10000, t.Rounding))
+	return TaxSplit{Net: amount, Tax: tax, Gross: amount + tax}
+}
+
+func (s TaxSplit) Add(o TaxSplit) TaxSplit {
+	return TaxSplit{Net: s.Net + o.Net, Tax: s.Tax + o.Tax, Gross: s.Gross + o.Gross}
+}
+
+// divRound divides a non-negative n by a positive d.
+func divRound(n, d int64, mode int) int64 {
+	q, r := n/d, n%d
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		if r != 0 {
+			q++
+		}
+	case RoundHalfEven:
+		if 2*r > d || (2*r == d && q%2 == 1) {
+			q++
+		}
+	default:
+		if 2*r >= d {
+			q++
+		}
+	}
+	return q
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestTaxSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		tax    Tax
+		amount Money
+		want   TaxSplit
+	}{
+		{"included", Tax{Rate: 2000, Included: true}, 12000, TaxSplit{Net: 10000, Tax: 2000, Gross: 12000}},
+		{"added", Tax{Rate: 2000}, 10000, TaxSplit{Net: 10000, Tax: 2000, Gross: 12000}},
+		// 20% included in 149.50 is 24.9166...
+		{"half up", Tax{Rate: 2000, Included: true}, 14950, TaxSplit{Net: 12458, Tax: 2492, Gross: 14950}},
+		{"down", Tax{Rate: 2000, Included: true, Rounding: RoundDown}, 14950, TaxSplit{Net: 12459, Tax: 2491, Gross: 14950}},
+		// 10% of 0.25 is exactly 0.025.
+		{"half up tie", Tax{Rate: 1000}, 25, TaxSplit{Net: 25, Tax: 3, Gross: 28}},
+		{"half even tie", Tax{Rate: 1000, Rounding: RoundHalfEven}, 25, TaxSplit{Net: 25, Tax: 2, Gross: 27}},
+		{"up", Tax{Rate: 1000, Rounding: RoundUp}, 21, TaxSplit{Net: 21, Tax: 3, Gross: 24}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tax.Split(tt.amount); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	for _, name := range []string{"half_up", "half_even", "down", "up"} {
+		mode, err := ParseRounding(name)
+		if err != nil || RoundingName(mode) != name {
+			t.Errorf("rounding %q did not round-trip: %d, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseRounding("bankers"); err == nil {
+		t.Error("expected an error for an unknown rounding mode")
+	}
+}
@@ -0,0 +1,193 @@
+package normalize
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Problem is something found while normalizing, Line is 1-based.
+type Problem struct {
+	Line int
+	Msg  string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Msg)
+}
+
+// line is an event line of the input with the comments written above it.
+type line struct {
+	num      int
+	text     string
+	event    *models.Event
+	at       time.Time
+	comments []string
+}
+
+// Normalize writes the event log read from r in canonical form: zero-padded
+// times, single spaces, blank lines dropped and events in stable time order.
+// Comments stay above the line they annotate, and correction references
+// follow the events they point to. Lines that cannot be parsed are kept as
+// they are and reported. An error is only returned when the configuration
+// cannot be read.
+func Normalize(w io.Writer, r io.Reader, configPath string, tf utils.TimeFormat) ([]*Problem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	raw := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	clean := make([]string, len(raw))
+	for i, text := range raw {
+		clean[i] = strings.Join(strings.Fields(text), " ")
+	}
+	cleaned := []byte(strings.Join(clean, "\n") + "\n")
+	cfg, err := config.Load(bufio.NewReader(bytes.NewReader(cleaned)), configPath, tf)
+	if err != nil {
+		return nil, err
+	}
+	header := configPath == "" || config.HasHeader(bufio.NewReader(bytes.NewReader(cleaned)))
+	h := handler.NewFileHandler(nil, nil, cfg)
+
+	var (
+		out      []string
+		lines    []*line
+		comments []string
+		problems []*Problem
+		last     *line
+		headerN  int
+	)
+	for i, text := range clean {
+		switch {
+		case text == "":
+			continue
+		case config.IsComment(text):
+			comments = append(comments, text)
+			continue
+		case header && headerN < 3:
+			out = append(out, comments...)
+			out = append(out, headerLine(headerN, text, tf))
+			comments = nil
+			headerN++
+			continue
+		}
+		l := &line{num: i + 1, text: text, comments: comments}
+		comments = nil
+		lines = append(lines, l)
+		e, err := h.ParseLine(text)
+		if err != nil {
+			problems = append(problems, &Problem{Line: l.num, Msg: "invalid event: " + text})
+			continue
+		}
+		l.event, l.text, l.at = e, e.Format(tf), e.Timestamp
+		if last != nil && l.at.Before(last.at) {
+			problems = append(problems, &Problem{Line: l.num,
+				Msg: "event at " + tf.Format(l.at) + " after " + tf.Format(last.at) + " moved into time order"})
+			continue
+		}
+		last = l
+	}
+
+	// Unparsed lines keep their place after the event above them.
+	var prev *line
+	for _, l := range lines {
+		if l.event != nil {
+			prev = l
+		} else if prev != nil {
+			l.at = prev.at
+		}
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].event != nil {
+			prev = lines[i]
+		} else if lines[i].at.IsZero() && prev != nil {
+			lines[i].at = prev.at
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].at.Before(lines[j].at)
+	})
+	problems = append(problems, renumber(lines, tf)...)
+
+	for _, l := range lines {
+		out = append(out, l.comments...)
+		out = append(out, l.text)
+	}
+	out = append(out, comments...)
+	for _, s := range out {
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return problems, err
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems, nil
+}
+
+// renumber points corrections at the new sequence numbers of the events
+// they correct. Sequence numbers count the events in input order.
+func renumber(lines []*line, tf utils.TimeFormat) []*Problem {
+	var events []*line
+	for _, l := range lines {
+		if l.event != nil {
+			events = append(events, l)
+		}
+	}
+	byNum := append([]*line(nil), events...)
+	sort.Slice(byNum, func(i, j int) bool {
+		return byNum[i].num < byNum[j].num
+	})
+	seq := make(map[*line]int, len(events))
+	for i, l := range events {
+		seq[l] = i + 1
+	}
+
+	var problems []*Problem
+	for _, l := range events {
+		if l.event.Code != models.EventCorrection {
+			continue
+		}
+		ref := l.event.Ref
+		if ref < 1 || ref > len(byNum) {
+			problems = append(problems, &Problem{Line: l.num, Msg: "correction of unknown event " + strconv.Itoa(ref)})
+			continue
+		}
+		target := byNum[ref-1]
+		l.event.Ref = seq[target]
+		l.text = l.event.Format(tf)
+		if l.event.Ref >= seq[l] {
+			problems = append(problems, &Problem{Line: l.num, Msg: "correction comes before the event it corrects"})
+		}
+	}
+	return problems
+}
+
+// headerLine writes the n-th header line in canonical form. The header was
+// already validated by the configuration.
+func headerLine(n int, text string, tf utils.TimeFormat) string {
+	fields := strings.Fields(text)
+	switch n {
+	case 0:
+		tables, _ := strconv.Atoi(fields[0])
+		return strconv.Itoa(tables)
+	case 1:
+		opening, _ := tf.Parse(fields[0])
+		closing, _ := tf.Parse(fields[1])
+		return tf.Format(opening) + " " + tf.Format(closing)
+	default:
+		rate, _ := models.ParseMoney(fields[0])
+		return rate.String()
+	}
+}
@@ -0,0 +1,75 @@
+package normalize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func TestNormalize(t *testing.T) {
+	input := `# merged by hand
+3
+9:00  19:00
+10.00
+
+# alice comes first
+09:10 1 alice
+  09:20   2 alice 02
+09:15 1 bob
+09:30 x bob
+09:40 5 2 void
+{"time": "09:50", "code": 4, "client": "alice"}
+# end of day
+`
+	want := `# merged by hand
+3
+09:00 19:00
+10
+# alice comes first
+09:10 1 alice
+09:15 1 bob
+09:30 x bob
+09:20 2 alice 2
+09:40 5 3 void
+09:50 4 alice
+# end of day
+`
+	var out strings.Builder
+	problems, err := Normalize(&out, strings.NewReader(input), "", utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	wantProblems := []string{
+		"line 9: event at 09:15 after 09:20 moved into time order",
+		"line 10: invalid event: 09:30 x bob",
+	}
+	if !reflect.DeepEqual(got, wantProblems) {
+		t.Errorf("Expected problems: %q, got: %q", wantProblems, got)
+	}
+
+	// Canonical input is left as it is.
+	out.Reset()
+	problems, err = Normalize(&out, strings.NewReader(strings.Replace(want, "09:30 x bob\n", "", 1)), "", utils.TimeFormat{})
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("unexpected problems %v, %v", problems, err)
+	}
+	if out.String() != strings.Replace(want, "09:30 x bob\n", "", 1) {
+		t.Errorf("canonical input changed:\n%s", out.String())
+	}
+}
+
+func TestNormalizeBadHeader(t *testing.T) {
+	var out strings.Builder
+	if _, err := Normalize(&out, strings.NewReader("3\n19:00 09:00\n10\n"), "", utils.TimeFormat{}); err == nil {
+		t.Error("expected an error for an invalid header")
+	}
+}
@@ -0,0 +1,224 @@
+package observer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+)
+
+// State is the part of the club state an Explainer reads.
+type State interface {
+	GetAllTables() map[int]*models.Table
+	GetQueue() []*models.Client
+}
+
+// Explainer writes the event log with every outgoing event followed by
+// the reason behind it after a #, for example
+//
+//	10:30 13 PlaceIsBusy # 10:30 2 alice 2: table 2 is held by bob since 09:20
+//
+// It reads the club state when an outgoing event is logged, so it explains
+// the decisions as they are made. Events recomputed after a correction are
+// not explained again.
+type Explainer struct {
+	mu    sync.Mutex
+	out   io.Writer
+	cfg   *config.Config
+	state State
+
+	incoming *models.Event
+	last     *models.Event
+	recent   []*models.Change
+}
+
+func NewExplainer(out io.Writer, cfg *config.Config, state State) *Explainer {
+	return &Explainer{out: out, cfg: cfg, state: state}
+}
+
+func (x *Explainer) OnEvent(e *models.Event, incoming bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if incoming {
+		x.incoming, x.last, x.recent = e, nil, nil
+		fmt.Fprintln(x.out, e.Format(x.cfg.Time))
+		return
+	}
+	fmt.Fprintf(x.out, "%s # %s\n", e.Format(x.cfg.Time), x.explain(e))
+	x.last = e
+}
+
+func (x *Explainer) OnChange(c *models.Change) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.recent = append(x.recent, c)
+}
+
+func (x *Explainer) explain(e *models.Event) string {
+	in := x.incoming
+	cause := "no incoming event"
+	if in != nil {
+		cause = in.Format(x.cfg.Time)
+	}
+	switch e.Code {
+	case models.EventError:
+		return cause + ": " + x.explainError(e.ErrorMsg, in)
+	case models.ClientSat, models.ClientSatFromQueue:
+		return x.explainSeat(e)
+	case models.ClientQueued:
+		if in != nil && in.Code == models.TableOutOfService {
+			return fmt.Sprintf("%s: table %d went out of service and no table is free, %s joins the queue; %s",
+				cause, in.TableID, e.ClientName, x.queue())
+		}
+	case models.ClientForceLeft:
+		return x.explainForceLeft(e)
+	case models.TableReleased:
+		return fmt.Sprintf("%s paused the session at table %d for longer than the maximum pause of %s",
+			e.ClientName, e.TableID, x.cfg.Time.FormatDuration(x.cfg.MaxPause))
+	}
+	return "rule for code " + strconv.Itoa(e.Code)
+}
+
+func (x *Explainer) explainError(err error, in *models.Event) string {
+	if in == nil {
+		return err.Error()
+	}
+	tables := x.state.GetAllTables()
+	switch {
+	case errors.Is(err, service.ErrNotOpenYet):
+		return fmt.Sprintf("arrivals are only accepted after opening at %s", x.cfg.Time.Format(x.cfg.OpeningTime))
+	case errors.Is(err, service.ErrYouShallNotPass):
+		return in.ClientName + " is already in the club"
+	case errors.Is(err, service.ErrPlaceIsBusy):
+		t := tables[in.TableID]
+		if t == nil || t.Client == nil {
+			return fmt.Sprintf("table %d is busy", in.TableID)
+		}
+		s := fmt.Sprintf("table %d is held by %s since %s", t.Id, t.Client.Name, x.cfg.Time.Format(t.ClientSat))
+		if !t.PausedAt.IsZero() {
+			s += ", paused since " + x.cfg.Time.Format(t.PausedAt)
+		}
+		return s
+	case errors.Is(err, service.ErrAlreadyAtTable):
+		if t := tables[in.TableID]; t != nil {
+			return fmt.Sprintf("%s already sits at table %d since %s", in.ClientName, t.Id, x.cfg.Time.Format(t.ClientSat))
+		}
+	case errors.Is(err, service.ErrClientUnknown):
+		return in.ClientName + " is not in the club"
+	case errors.Is(err, service.ErrICanWaitNoLonger):
+		return "clients may not wait while a table is free, free tables: " + x.freeTables()
+	case errors.Is(err, service.ErrOutOfService):
+		if t := tables[in.TableID]; t != nil && t.OutOfService {
+			return fmt.Sprintf("table %d is out of service since %s", t.Id, x.cfg.Time.Format(t.OutSince))
+		}
+		return fmt.Sprintf("table %d is out of service", in.TableID)
+	case errors.Is(err, service.ErrNotSeated):
+		return in.ClientName + " holds no table"
+	case errors.Is(err, service.ErrAlreadyPaused):
+		if t := tableOf(tables, in.ClientName); t != nil {
+			return fmt.Sprintf("%s paused table %d at %s", in.ClientName, t.Id, x.cfg.Time.Format(t.PausedAt))
+		}
+	case errors.Is(err, service.ErrNotPaused):
+		if t := tableOf(tables, in.ClientName); t != nil {
+			return fmt.Sprintf("the session of %s at table %d is not paused", in.ClientName, t.Id)
+		}
+	}
+	return err.Error()
+}
+
+// explainSeat explains a client seated by the club: from the queue at a
+// table that became free, or moved off a table going out of service.
+func (x *Explainer) explainSeat(e *models.Event) string {
+	if x.last != nil && x.last.Code == models.TableReleased && x.last.TableID == e.TableID {
+		return fmt.Sprintf("table %d was released by %s, %s was first in the queue; %s",
+			e.TableID, x.last.ClientName, e.ClientName, x.queue())
+	}
+	in := x.incoming
+	if in == nil {
+		return fmt.Sprintf("%s was seated at table %d", e.ClientName, e.TableID)
+	}
+	cause := in.Format(x.cfg.Time)
+	switch in.Code {
+	case models.TableOutOfService:
+		return fmt.Sprintf("%s: table %d went out of service, table %d was the lowest free table",
+			cause, in.TableID, e.TableID)
+	case models.TableInService:
+		return fmt.Sprintf("%s: table %d is back in service, %s was first in the queue; %s",
+			cause, e.TableID, e.ClientName, x.queue())
+	}
+	freedBy := "its client"
+	for _, c := range x.recent {
+		if c.Kind == models.TableFreed && c.TableID == e.TableID {
+			freedBy = c.ClientName
+		}
+	}
+	return fmt.Sprintf("%s: table %d was freed by %s, %s was first in the queue; %s",
+		cause, e.TableID, freedBy, e.ClientName, x.queue())
+}
+
+func (x *Explainer) explainForceLeft(e *models.Event) string {
+	in := x.incoming
+	if in != nil && e.Timestamp.Equal(in.Timestamp) && in.ClientName == e.ClientName && in.Code == models.ClientWaiting {
+		return fmt.Sprintf("%s: the queue is full (capacity %d); %s", in.Format(x.cfg.Time), x.cfg.QueueCapacity, x.queue())
+	}
+	if in != nil && e.Timestamp.Equal(in.Timestamp) && in.Code == models.TableOutOfService {
+		return fmt.Sprintf("%s: table %d went out of service, no table is free and the queue is full (capacity %d)",
+			in.Format(x.cfg.Time), in.TableID, x.cfg.QueueCapacity)
+	}
+	where := "in the club"
+	for _, c := range x.recent {
+		if c.ClientName != e.ClientName || !c.Timestamp.Equal(e.Timestamp) {
+			continue
+		}
+		switch c.Kind {
+		case models.TableFreed:
+			where = fmt.Sprintf("at table %d", c.TableID)
+		case models.QueueLeft:
+			where = "in the queue"
+		}
+	}
+	return fmt.Sprintf("the club closes at %s and %s was still %s", x.cfg.Time.Format(x.cfg.ClosingTime), e.ClientName, where)
+}
+
+func (x *Explainer) queue() string {
+	clients := x.state.GetQueue()
+	if len(clients) == 0 {
+		return "queue now empty"
+	}
+	names := make([]string, 0, len(clients))
+	for _, c := range clients {
+		names = append(names, c.Name)
+	}
+	return "queue now: " + strings.Join(names, ", ")
+}
+
+func (x *Explainer) freeTables() string {
+	var free []int
+	for id, t := range x.state.GetAllTables() {
+		if t.Client == nil && !t.OutOfService {
+			free = append(free, id)
+		}
+	}
+	slices.Sort(free)
+	ids := make([]string, 0, len(free))
+	for _, id := range free {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	return strings.Join(ids, ", ")
+}
+
+func tableOf(tables map[int]*models.Table, name string) *models.Table {
+	for _, t := range tables {
+		if t.Client != nil && t.Client.Name == name {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package observer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Logger writes every event and change as a line of text, with times in
+// the format tf. It is safe to share between several clubs processed in
+// parallel.
+type Logger struct {
+	mu  sync.Mutex
+	out io.Writer
+	tf  utils.TimeFormat
+}
+
+func NewLogger(out io.Writer, tf utils.TimeFormat) *Logger {
+	return &Logger{out: out, tf: tf}
+}
+
+func (l *Logger) OnEvent(e *models.Event, incoming bool) {
+	dir := "out"
+	if incoming {
+		dir = "in"
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, "%s %s\n", dir, e.Format(l.tf))
+}
+
+func (l *Logger) OnChange(c *models.Change) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, "state %s\n", c.Format(l.tf))
+}
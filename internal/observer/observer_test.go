@@ -0,0 +1,186 @@
+package observer
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const day = `1
+09:00 19:00
+10
+09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:31 3 bob
+09:40 1 carol
+09:41 3 carol
+10:00 4 alice
+`
+
+func run(t *testing.T, observers ...service.Observer) {
+	t.Helper()
+	cfg, err := config.Load(bufio.NewReader(strings.NewReader(day)), "", utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.QueueCapacity = 1
+	svc := service.New(cfg, storage.NewInMemRepo(cfg))
+	for _, o := range observers {
+		svc.AddObserver(o)
+	}
+	h := handler.NewFileHandler(cfg.FileScanner, svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatal(err)
+	}
+	h.Report()
+}
+
+func TestLogger(t *testing.T) {
+	var b strings.Builder
+	run(t, NewLogger(&b, utils.TimeFormat{}))
+	want := `in 09:10 1 alice
+in 09:20 2 alice 1
+state 09:20 table_occupied alice 1
+in 09:30 1 bob
+in 09:31 3 bob
+state 09:31 queue_joined bob
+in 09:40 1 carol
+in 09:41 3 carol
+out 09:41 11 carol
+in 10:00 4 alice
+state 10:00 table_freed alice 1
+state 10:00 queue_left bob
+state 10:00 table_occupied bob 1
+out 10:00 2 bob 1
+state 19:00 table_freed bob 1
+out 19:00 11 bob
+out 19:00 11 carol
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var got []payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		got = append(got, p)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	run(t, NewWebhook(srv.URL, []int{11}))
+	if len(got) != 3 {
+		t.Fatalf("expected 3 posts, got %d: %+v", len(got), got)
+	}
+	if got[0].Client != "carol" || got[0].Time != "09:41" || got[0].Code != 11 || got[0].Incoming {
+		t.Errorf("unexpected first post: %+v", got[0])
+	}
+}
+
+func TestExplainer(t *testing.T) {
+	cfg, err := config.Load(bufio.NewReader(strings.NewReader(day+"10:05 2 carol 1\n")), "", utils.TimeFormat{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.QueueCapacity = 1
+	repo := storage.NewInMemRepo(cfg)
+	svc := service.New(cfg, repo)
+	var b strings.Builder
+	svc.AddObserver(NewExplainer(&b, cfg, repo))
+	h := handler.NewFileHandler(cfg.FileScanner, svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatal(err)
+	}
+	h.Report()
+	want := `09:10 1 alice
+09:20 2 alice 1
+09:30 1 bob
+09:31 3 bob
+09:40 1 carol
+09:41 3 carol
+09:41 11 carol # 09:41 3 carol: the queue is full (capacity 1); queue now: bob
+10:00 4 alice
+10:00 2 bob 1 # 10:00 4 alice: table 1 was freed by alice, bob was first in the queue; queue now empty
+10:05 2 carol 1
+10:05 13 PlaceIsBusy # 10:05 2 carol 1: table 1 is held by bob since 10:00
+19:00 11 bob # the club closes at 19:00 and bob was still at table 1
+19:00 11 carol # the club closes at 19:00 and carol was still in the club
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// TestExplainerExtendedTime checks that the causes keep the seconds of the
+// incoming events.
+func TestExplainerExtendedTime(t *testing.T) {
+	log := `2
+09:00:00 19:00:00
+10
+09:10:05 1 alice
+09:10:20 2 alice 1
+09:20:00 1 bob
+09:20:10 2 bob 2
+09:30:00 1 carol
+09:30:15 3 carol
+10:00:30 6 2
+10:10:00 1 dave
+10:10:05 3 dave
+10:30:45 4 alice
+10:40:50 7 2
+`
+	cfg, err := config.Load(bufio.NewReader(strings.NewReader(log)), "", utils.TimeFormat{Extended: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.QueueCapacity = 2
+	repo := storage.NewInMemRepo(cfg)
+	svc := service.New(cfg, repo)
+	var b strings.Builder
+	svc.AddObserver(NewExplainer(&b, cfg, repo))
+	h := handler.NewFileHandler(cfg.FileScanner, svc, cfg)
+	if err := h.ProcessEvents(); err != nil {
+		t.Fatal(err)
+	}
+	h.Report()
+	want := `09:10:05 1 alice
+09:10:20 2 alice 1
+09:20:00 1 bob
+09:20:10 2 bob 2
+09:30:00 1 carol
+09:30:15 3 carol
+10:00:30 6 2
+10:00:30 15 bob # 10:00:30 6 2: table 2 went out of service and no table is free, bob joins the queue; queue now: carol, bob
+10:10:00 1 dave
+10:10:05 3 dave
+10:10:05 11 dave # 10:10:05 3 dave: the queue is full (capacity 2); queue now: carol, bob
+10:30:45 4 alice
+10:30:45 2 carol 1 # 10:30:45 4 alice: table 1 was freed by alice, carol was first in the queue; queue now: bob
+10:40:50 7 2
+10:40:50 12 bob 2 # 10:40:50 7 2: table 2 is back in service, bob was first in the queue; queue now empty
+19:00:00 11 bob # the club closes at 19:00:00 and bob was still at table 2
+19:00:00 11 carol # the club closes at 19:00:00 and carol was still at table 1
+19:00:00 11 dave # the club closes at 19:00:00 and dave was still in the club
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,93 @@
+package observer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Webhook posts events as JSON to a URL. Codes limits the posted events to
+// the given codes, all events are posted when it is empty. Changes are only
+// posted when Changes is set. Times are posted in the format Time. A failed
+// post is logged and does not stop processing.
+type Webhook struct {
+	URL     string
+	Codes   []int
+	Changes bool
+	Time    utils.TimeFormat
+	Client  *http.Client
+}
+
+type payload struct {
+	Kind     string `json:"kind"`
+	Incoming bool   `json:"incoming,omitempty"`
+	Time     string `json:"time"`
+	Code     int    `json:"code,omitempty"`
+	Change   string `json:"change,omitempty"`
+	Client   string `json:"client,omitempty"`
+	Table    int    `json:"table,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func NewWebhook(url string, codes []int) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Codes:  codes,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (w *Webhook) OnEvent(e *models.Event, incoming bool) {
+	if len(w.Codes) > 0 && !slices.Contains(w.Codes, e.Code) {
+		return
+	}
+	p := &payload{
+		Kind:     "event",
+		Incoming: incoming,
+		Time:     w.Time.Format(e.Timestamp),
+		Code:     e.Code,
+		Client:   e.ClientName,
+		Table:    e.TableID,
+	}
+	if e.ErrorMsg != nil {
+		p.Error = e.ErrorMsg.Error()
+	}
+	w.post(p)
+}
+
+func (w *Webhook) OnChange(c *models.Change) {
+	if !w.Changes {
+		return
+	}
+	w.post(&payload{
+		Kind:   "change",
+		Time:   w.Time.Format(c.Timestamp),
+		Change: models.ChangeName(c.Kind),
+		Client: c.ClientName,
+		Table:  c.TableID,
+	})
+}
+
+func (w *Webhook) post(p *payload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("webhook: %v", err)
+		return
+	}
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("webhook: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("webhook: %v", fmt.Errorf("%s returned %s", w.URL, resp.Status))
+	}
+}
@@ -0,0 +1,243 @@
+package receipt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Receipt is what a client owes for one visit. Each table session is billed
+// for the hours it adds to its table, so the receipts of a day add up to the
+// revenue in the report.
+type Receipt struct {
+	ClientName string
+	Arrived    time.Time
+	Left       time.Time
+	Forced     bool
+	Sessions   []*Session
+	Extras     []*models.Extra
+	Discounts  []*Discount
+	Tables     models.Money
+	ExtrasSum  models.Money
+	Total      models.Money
+
+	// Time is the format that timestamps and durations are written in.
+	Time utils.TimeFormat
+}
+
+type Session struct {
+	TableID int
+	Start   time.Time
+	End     time.Time
+	Played  time.Duration
+	Hours   int
+	Rate    models.Money
+	Charge  models.Money
+}
+
+type Discount struct {
+	Name   string
+	Amount models.Money
+}
+
+func New(v *models.Visit, cfg *config.Config) *Receipt {
+	r := &Receipt{
+		ClientName: v.ClientName,
+		Arrived:    v.Arrived,
+		Left:       v.Left,
+		Forced:     v.Forced,
+		Extras:     v.Extras,
+		Time:       cfg.Time,
+	}
+	var discount models.Money
+	for _, s := range v.Sessions {
+		rs := &Session{
+			TableID: s.TableID,
+			Start:   s.Start,
+			End:     s.End,
+			Played:  s.Duration(),
+			Hours:   s.Hours,
+			Rate:    cfg.RateFor(s.TableID),
+		}
+		rs.Charge = rs.Rate.Times(rs.Hours)
+		discount += cfg.Discount.Amount(rs.Charge, v.Hours())
+		r.Tables += rs.Charge
+		r.Sessions = append(r.Sessions, rs)
+	}
+	r.ExtrasSum = v.Tab()
+	r.Total = r.Tables + r.ExtrasSum
+
+	// The discount applies to table time only, it is taken off each session
+	// like in the report's revenue.
+	if discount > 0 {
+		r.Discounts = append(r.Discounts, &Discount{
+			Name:   fmt.Sprintf("%d%% off table time", cfg.Discount.Percent),
+			Amount: discount,
+		})
+		r.Total -= discount
+	}
+	return r
+}
+
+// FileName names the receipt after the client and the arrival time, which
+// is unique within a day.
+func (r *Receipt) FileName(format string) string {
+	ext := ".txt"
+	if format == FormatJSON {
+		ext = ".json"
+	}
+	return r.ClientName + "-" + strings.ReplaceAll(r.Time.Format(r.Arrived), ":", "") + ext
+}
+
+func ValidFormat(format string) bool {
+	switch format {
+	case "", FormatText, FormatJSON:
+		return true
+	}
+	return false
+}
+
+func WriteFile(dir, format string, r *Receipt) error {
+	f, err := os.Create(filepath.Join(dir, r.FileName(format)))
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RemoveFile removes a receipt written by WriteFile. A receipt that was
+// never written is not an error.
+func RemoveFile(dir, format string, r *Receipt) error {
+	err := os.Remove(filepath.Join(dir, r.FileName(format)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func Write(w io.Writer, format string, r *Receipt) error {
+	switch format {
+	case "", FormatText:
+		return writeText(w, r)
+	case FormatJSON:
+		return writeJSON(w, r)
+	}
+	return fmt.Errorf("unknown receipt format %q", format)
+}
+
+func writeText(w io.Writer, r *Receipt) error {
+	left := r.Time.Format(r.Left)
+	if r.Forced {
+		left += " (closing)"
+	}
+	fmt.Fprintf(w, "Receipt: %s\n", r.ClientName)
+	fmt.Fprintf(w, "Visit: %s-%s\n", r.Time.Format(r.Arrived), left)
+	for _, s := range r.Sessions {
+		fmt.Fprintf(w, "Table %d %s-%s %s: %d h x %s = %s\n", s.TableID, r.Time.Format(s.Start),
+			r.Time.Format(s.End), r.Time.FormatDuration(s.Played), s.Hours, s.Rate, s.Charge)
+	}
+	for _, e := range r.Extras {
+		fmt.Fprintf(w, "%s %s: %d x %s = %s\n", r.Time.Format(e.Timestamp), e.Item, e.Quantity, e.Price, e.Sum())
+	}
+	for _, d := range r.Discounts {
+		fmt.Fprintf(w, "Discount %s: -%s\n", d.Name, d.Amount)
+	}
+	_, err := fmt.Fprintf(w, "Total: %s\n", r.Total)
+	return err
+}
+
+type jsonReceipt struct {
+	Client    string          `json:"client"`
+	Arrived   string          `json:"arrived"`
+	Left      string          `json:"left"`
+	Forced    bool            `json:"forced"`
+	Sessions  []*jsonSession  `json:"sessions"`
+	Extras    []*jsonExtra    `json:"extras"`
+	Discounts []*jsonDiscount `json:"discounts"`
+	Tables    models.Money    `json:"tables"`
+	ExtrasSum models.Money    `json:"extras_total"`
+	Total     models.Money    `json:"total"`
+}
+
+type jsonSession struct {
+	Table  int          `json:"table"`
+	Start  string       `json:"start"`
+	End    string       `json:"end"`
+	Played string       `json:"played"`
+	Hours  int          `json:"hours"`
+	Rate   models.Money `json:"rate"`
+	Charge models.Money `json:"charge"`
+}
+
+type jsonExtra struct {
+	Time     string       `json:"time"`
+	Item     string       `json:"item"`
+	Price    models.Money `json:"price"`
+	Quantity int          `json:"quantity"`
+	Charge   models.Money `json:"charge"`
+}
+
+type jsonDiscount struct {
+	Name   string       `json:"name"`
+	Amount models.Money `json:"amount"`
+}
+
+func writeJSON(w io.Writer, r *Receipt) error {
+	out := &jsonReceipt{
+		Client:    r.ClientName,
+		Arrived:   r.Time.Format(r.Arrived),
+		Left:      r.Time.Format(r.Left),
+		Forced:    r.Forced,
+		Sessions:  make([]*jsonSession, 0, len(r.Sessions)),
+		Extras:    make([]*jsonExtra, 0, len(r.Extras)),
+		Discounts: make([]*jsonDiscount, 0, len(r.Discounts)),
+		Tables:    r.Tables,
+		ExtrasSum: r.ExtrasSum,
+		Total:     r.Total,
+	}
+	for _, s := range r.Sessions {
+		out.Sessions = append(out.Sessions, &jsonSession{
+			Table:  s.TableID,
+			Start:  r.Time.Format(s.Start),
+			End:    r.Time.Format(s.End),
+			Played: r.Time.FormatDuration(s.Played),
+			Hours:  s.Hours,
+			Rate:   s.Rate,
+			Charge: s.Charge,
+		})
+	}
+	for _, e := range r.Extras {
+		out.Extras = append(out.Extras, &jsonExtra{
+			Time:     r.Time.Format(e.Timestamp),
+			Item:     e.Item,
+			Price:    e.Price,
+			Quantity: e.Quantity,
+			Charge:   e.Sum(),
+		})
+	}
+	for _, d := range r.Discounts {
+		out.Discounts = append(out.Discounts, &jsonDiscount{Name: d.Name, Amount: d.Amount})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,66 @@
+package receipt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func parse(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := utils.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestReceipt(t *testing.T) {
+	cfg := &config.Config{
+		HourlyRate: 1000,
+		Zones:      []config.Zone{{Name: "vip", Tables: []int{2}, HourlyRate: 2550}},
+		Discount:   config.Discount{Percent: 10, MinHours: 3},
+	}
+	visit := &models.Visit{
+		ClientName: "alice",
+		Arrived:    parse(t, "09:10"),
+		Left:       parse(t, "19:00"),
+		Forced:     true,
+		Sessions: []*models.Session{
+			{TableID: 1, ClientName: "alice", Start: parse(t, "09:20"), End: parse(t, "10:00"), Hours: 1},
+			{TableID: 2, ClientName: "alice", Start: parse(t, "10:00"), End: parse(t, "12:30"), Hours: 2,
+				Pauses: []*models.Pause{{Start: parse(t, "11:00"), End: parse(t, "11:40")}}},
+		},
+		Extras: []*models.Extra{
+			{ClientName: "alice", Item: "cola", Price: 5000, Quantity: 2, Timestamp: parse(t, "09:15")},
+		},
+	}
+	want := `Receipt: alice
+Visit: 09:10-19:00 (closing)
+Table 1 09:20-10:00 00:40: 1 h x 10 = 10
+Table 2 10:00-12:30 01:50: 2 h x 25.50 = 51
+09:15 cola: 2 x 50 = 100
+Discount 10% off table time: -6.10
+Total: 154.90
+`
+	r := New(visit, cfg)
+	var b strings.Builder
+	if err := Write(&b, FormatText, r); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if name := r.FileName(FormatJSON); name != "alice-0910.json" {
+		t.Errorf("unexpected file name %q", name)
+	}
+
+	cfg.Discount.MinHours = 4
+	if r := New(visit, cfg); len(r.Discounts) != 0 || r.Total != 16100 {
+		t.Errorf("expected no discount below min hours, got total %s", r.Total)
+	}
+}
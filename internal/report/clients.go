@@ -0,0 +1,85 @@
+package report
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+type ClientSummary struct {
+	Name   string
+	Visits []*ClientVisit
+	Errors []*models.Event
+	Played time.Duration
+	Waited time.Duration
+	Total  models.Money
+}
+
+type ClientVisit struct {
+	*models.Visit
+	Sessions []*ChargedSession
+}
+
+type ChargedSession struct {
+	*models.Session
+	Charge models.Money
+}
+
+// NewClientSummaries groups visits by client. Every session is charged for
+// the hours it adds to its table at the rate of the table, like on the
+// receipt. Error events
+// carry no client name, they are attributed to the client of the incoming
+// event they follow.
+func NewClientSummaries(visits []*models.Visit, events []*models.Event, rate func(tableID int) models.Money) []*ClientSummary {
+	byName := make(map[string]*ClientSummary)
+	summary := func(name string) *ClientSummary {
+		s, ok := byName[name]
+		if !ok {
+			s = &ClientSummary{Name: name}
+			byName[name] = s
+		}
+		return s
+	}
+
+	for _, v := range visits {
+		s := summary(v.ClientName)
+		cv := &ClientVisit{Visit: v}
+		for _, session := range v.Sessions {
+			cs := &ChargedSession{
+				Session: session,
+				Charge:  rate(session.TableID).Times(session.Hours),
+			}
+			cv.Sessions = append(cv.Sessions, cs)
+			s.Played += session.Duration()
+			s.Total += cs.Charge
+		}
+		for _, w := range v.Waits {
+			s.Waited += w.Duration()
+		}
+		s.Total += v.Tab()
+		s.Visits = append(s.Visits, cv)
+	}
+
+	var last string
+	for _, e := range events {
+		if e.Code != models.EventError {
+			last = e.ClientName
+			continue
+		}
+		if last != "" {
+			s := summary(last)
+			s.Errors = append(s.Errors, e)
+		}
+	}
+
+	summaries := make([]*ClientSummary, 0, len(byName))
+	for _, s := range byName {
+		summaries = append(summaries, s)
+	}
+	slices.SortFunc(summaries, func(a, b *ClientSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return summaries
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+)
+
+func TestNewClientSummaries(t *testing.T) {
+	sessions := []*models.Session{
+		{TableID: 1, ClientName: "bob", Start: parse(t, "10:00"), End: parse(t, "10:20"), Hours: 1},
+		{TableID: 2, ClientName: "bob", Start: parse(t, "10:20"), End: parse(t, "12:30"), Hours: 3},
+	}
+	visits := []*models.Visit{
+		{
+			ClientName: "bob",
+			Arrived:    parse(t, "09:50"),
+			Left:       parse(t, "12:30"),
+			Sessions:   sessions,
+			Waits: []*models.Wait{
+				{ClientName: "bob", Start: parse(t, "09:55"), End: parse(t, "10:00"), Outcome: models.WaitSeated},
+			},
+		},
+	}
+	events := []*models.Event{
+		{Code: models.ClientArrived, Timestamp: parse(t, "09:40"), ClientName: "eve"},
+		{Code: models.EventError, Timestamp: parse(t, "09:40"), ErrorMsg: service.ErrNotOpenYet},
+		{Code: models.ClientSat, Timestamp: parse(t, "10:30"), ClientName: "bob", TableID: 1},
+		{Code: models.EventError, Timestamp: parse(t, "10:30"), ErrorMsg: service.ErrPlaceIsBusy},
+	}
+	rate := func(tableID int) models.Money {
+		return models.Money(tableID) * 1000
+	}
+
+	summaries := NewClientSummaries(visits, events, rate)
+
+	if len(summaries) != 2 || summaries[0].Name != "bob" || summaries[1].Name != "eve" {
+		t.Fatalf("Expected summaries for bob and eve, got %v", summaries)
+	}
+	bob := summaries[0]
+	if bob.Total != 1*1000+3*2000 {
+		t.Errorf("Expected total 70, got %s", bob.Total)
+	}
+	if bob.Played != 150*time.Minute || bob.Waited != 5*time.Minute {
+		t.Errorf("Unexpected durations: played %v, waited %v", bob.Played, bob.Waited)
+	}
+	if len(bob.Errors) != 1 || bob.Errors[0].ErrorMsg != service.ErrPlaceIsBusy {
+		t.Errorf("Expected PlaceIsBusy attributed to bob, got %v", bob.Errors)
+	}
+	if eve := summaries[1]; len(eve.Visits) != 0 || len(eve.Errors) != 1 {
+		t.Errorf("Expected eve to have one error and no visits, got %+v", eve)
+	}
+}
@@ -0,0 +1,175 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// writeCSV writes every section of the day as its own table with a header
+// row, tables are separated by an empty line.
+func writeCSV(w io.Writer, d *Day) error {
+	var sections [][][]string
+
+	events := [][]string{{"time", "code", "client", "table", "error"}}
+	for _, e := range d.Events {
+		je := newJSONEvent(e, d.Time)
+		events = append(events, []string{je.Time, strconv.Itoa(je.Code), je.Client, optionalInt(je.Table), je.Error})
+	}
+	sections = append(sections, events)
+
+	profits := [][]string{{"table", "revenue", "occupied"}}
+	for _, p := range d.Profits {
+		profits = append(profits, []string{strconv.Itoa(p.Table.Id), p.Sum.String(), d.Time.FormatDuration(p.Table.TotalTime)})
+	}
+	sections = append(sections, profits)
+
+	if hasDowntime(d.Profits) {
+		downtime := [][]string{{"table", "downtime"}}
+		for _, p := range d.Profits {
+			if p.Table.Downtime > 0 {
+				downtime = append(downtime, []string{strconv.Itoa(p.Table.Id), d.Time.FormatDuration(p.Table.Downtime)})
+			}
+		}
+		sections = append(sections, downtime)
+	}
+
+	if r := d.Revenue; r != nil {
+		revenue := [][]string{{"table", "time", "extras", "discounts", "total"}}
+		for _, t := range r.Tables {
+			revenue = append(revenue, []string{strconv.Itoa(t.TableID), t.Table.String(),
+				t.Extras.String(), t.Discount.String(), t.Total.String()})
+		}
+		revenue = append(revenue, []string{"none", "0", r.Unseated.String(), "0", r.Unseated.String()})
+		revenue = append(revenue, []string{"total", r.Table.String(), r.Extras.String(), r.Discounts.String(), r.Total.String()})
+		sections = append(sections, revenue)
+	}
+
+	if t := d.Tax; t != nil {
+		taxes := [][]string{{"table", "net", "tax", "gross"}}
+		for _, row := range t.Tables {
+			taxes = append(taxes, taxCSV(strconv.Itoa(row.TableID), row.TaxSplit))
+		}
+		if t.Unseated != nil {
+			taxes = append(taxes, taxCSV("none", *t.Unseated))
+		}
+		taxes = append(taxes, taxCSV("total", t.Total))
+		sections = append(sections, taxes)
+	}
+
+	if d.Stats != nil {
+		sections = append(sections, statsCSV(d.Stats, d.Time))
+	}
+
+	if d.Clients != nil {
+		clients := [][]string{{"client", "arrived", "left", "kind", "table", "start", "end", "duration", "charge"}}
+		for _, c := range d.Clients {
+			for _, v := range c.Visits {
+				arrived, left := d.Time.Format(v.Arrived), d.Time.Format(v.Left)
+				for _, wait := range v.Waits {
+					clients = append(clients, []string{c.Name, arrived, left, "queue", "",
+						d.Time.Format(wait.Start), d.Time.Format(wait.End), d.Time.FormatDuration(wait.Duration()), ""})
+				}
+				for _, s := range v.Sessions {
+					clients = append(clients, []string{c.Name, arrived, left, "table", strconv.Itoa(s.TableID),
+						d.Time.Format(s.Start), d.Time.Format(s.End), d.Time.FormatDuration(s.Duration()), s.Charge.String()})
+				}
+				for _, e := range v.Extras {
+					clients = append(clients, []string{c.Name, arrived, left, "extra " + e.Item, optionalInt(e.TableID),
+						d.Time.Format(e.Timestamp), "", "", e.Sum().String()})
+				}
+			}
+			for _, e := range c.Errors {
+				clients = append(clients, []string{c.Name, "", "", "error", "",
+					d.Time.Format(e.Timestamp), "", "", e.ErrorMsg.Error()})
+			}
+		}
+		sections = append(sections, clients)
+	}
+
+	if d.Histogram != nil {
+		buckets := [][]string{{"start", "end", "occupied", "peak_occupied", "peak_queue", "revenue"}}
+		for _, b := range d.Histogram.Buckets {
+			buckets = append(buckets, []string{d.Time.Format(b.Start), d.Time.Format(b.End),
+				strconv.FormatFloat(b.Occupied, 'f', 2, 64), strconv.Itoa(b.PeakOccupied),
+				strconv.Itoa(b.PeakQueue), b.Revenue.String()})
+		}
+		sections = append(sections, buckets)
+	}
+
+	if len(d.Corrections) > 0 {
+		corrections := [][]string{{"seq", "time", "ref", "action", "original", "amended"}}
+		for _, c := range d.Corrections {
+			action, amended := "void", ""
+			if c.Amended != nil {
+				action, amended = "amend", c.Amended.Format(d.Time)
+			}
+			corrections = append(corrections, []string{strconv.Itoa(c.Seq), d.Time.Format(c.Timestamp),
+				strconv.Itoa(c.Ref), action, c.Original.Format(d.Time), amended})
+		}
+		sections = append(sections, corrections)
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statsCSV(s *Stats, tf utils.TimeFormat) [][]string {
+	rows := [][]string{{"metric", "table", "value"}}
+	for _, t := range s.Tables {
+		id := strconv.Itoa(t.ID)
+		rows = append(rows,
+			[]string{"sessions", id, strconv.Itoa(t.Sessions)},
+			[]string{"occupied", id, tf.FormatDuration(t.Occupied)},
+			[]string{"utilization", id, strconv.FormatFloat(t.Utilization, 'f', 1, 64)},
+		)
+	}
+	q := s.Queue
+	rows = append(rows,
+		[]string{"queue_waited", "", strconv.Itoa(q.Waited)},
+		[]string{"queue_average_wait", "", tf.FormatDuration(q.AverageWait)},
+		[]string{"queue_max_wait", "", tf.FormatDuration(q.MaxWait)},
+		[]string{"queue_gave_up", "", strconv.Itoa(q.GaveUp)},
+		[]string{"queue_left_while_waiting", "", strconv.Itoa(q.LeftWhileWaiting)},
+		[]string{"queue_unserved_at_close", "", strconv.Itoa(q.UnservedAtClose)},
+		[]string{"peak_occupancy", "", strconv.Itoa(s.PeakOccupancy)},
+	)
+	if s.PeakOccupancy > 0 {
+		rows = append(rows, []string{"peak_time", "", tf.Format(s.PeakTime)})
+	}
+	names := make([]string, 0, len(s.Errors))
+	for name := range s.Errors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		rows = append(rows, []string{"error_" + name, "", strconv.Itoa(s.Errors[name])})
+	}
+	return rows
+}
+
+func taxCSV(table string, s models.TaxSplit) []string {
+	return []string{table, s.Net.Decimal(), s.Tax.Decimal(), s.Gross.Decimal()}
+}
+
+func optionalInt(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
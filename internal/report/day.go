@@ -0,0 +1,33 @@
+package report
+
+import (
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+type Day struct {
+	Opening   time.Time
+	Closing   time.Time
+	Events    []*models.Event
+	Profits   []*models.Profit
+	Sessions  []*models.Session
+	Waits     []*models.Wait
+	Stats     *Stats
+	Clients   []*ClientSummary
+	Histogram *Histogram
+	Revenue   *Revenue
+	Tax       *Taxes
+
+	// Corrections is the audit trail of voided and amended events.
+	Corrections []*models.Correction
+
+	// Streamed marks a report whose opening time and first StreamedEvents
+	// events were already written while the day was going on.
+	Streamed       bool
+	StreamedEvents int
+
+	// Time is the format that timestamps and durations are written in.
+	Time utils.TimeFormat
+}
@@ -0,0 +1,145 @@
+package report
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+type Histogram struct {
+	Size    time.Duration
+	Buckets []*Bucket
+}
+
+type Bucket struct {
+	Start        time.Time
+	End          time.Time
+	Occupied     float64
+	PeakOccupied int
+	PeakQueue    int
+	Revenue      models.Money
+}
+
+// NewHistogram splits the opening window into buckets of the given size,
+// the last one ending at closing. Revenue follows the per-table rounding of
+// the day report: a table is charged one hourly rate at the moment each new
+// started hour of its cumulative occupied time begins, and that charge goes
+// to the bucket containing the moment. Bucket revenues of a table therefore
+// add up to its revenue in the day report.
+func NewHistogram(opening, closing time.Time, size time.Duration, sessions []*models.Session,
+	waits []*models.Wait, rate func(tableID int) models.Money) *Histogram {
+	h := &Histogram{Size: size}
+	for start := opening; start.Before(closing); start = start.Add(size) {
+		end := start.Add(size)
+		if end.After(closing) {
+			end = closing
+		}
+		b := &Bucket{Start: start, End: end}
+		var occupied time.Duration
+		for _, s := range sessions {
+			occupied += overlap(s.Start, s.End, start, end)
+		}
+		b.Occupied = float64(occupied) / float64(end.Sub(start))
+		b.PeakOccupied = peakWithin(start, end, sessionIntervals(sessions))
+		b.PeakQueue = peakWithin(start, end, waitIntervals(waits))
+		h.Buckets = append(h.Buckets, b)
+	}
+
+	for _, charge := range hourlyCharges(sessions, rate) {
+		for _, b := range h.Buckets {
+			if !charge.at.Before(b.Start) && charge.at.Before(b.End) {
+				b.Revenue += charge.sum
+				break
+			}
+		}
+	}
+	return h
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+type charge struct {
+	at  time.Time
+	sum models.Money
+}
+
+func sessionIntervals(sessions []*models.Session) []interval {
+	out := make([]interval, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, interval{s.Start, s.End})
+	}
+	return out
+}
+
+func waitIntervals(waits []*models.Wait) []interval {
+	out := make([]interval, 0, len(waits))
+	for _, w := range waits {
+		out = append(out, interval{w.Start, w.End})
+	}
+	return out
+}
+
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	start, end := aStart, aEnd
+	if bStart.After(start) {
+		start = bStart
+	}
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// peakWithin counts intervals open at the bucket start and at every interval
+// start inside the bucket, the only moments the count can grow.
+func peakWithin(start, end time.Time, intervals []interval) int {
+	moments := []time.Time{start}
+	for _, iv := range intervals {
+		if iv.start.After(start) && iv.start.Before(end) {
+			moments = append(moments, iv.start)
+		}
+	}
+	peak := 0
+	for _, m := range moments {
+		count := 0
+		for _, iv := range intervals {
+			if !iv.start.After(m) && iv.end.After(m) {
+				count++
+			}
+		}
+		peak = max(peak, count)
+	}
+	return peak
+}
+
+func hourlyCharges(sessions []*models.Session, rate func(tableID int) models.Money) []charge {
+	byTable := make(map[int][]*models.Session)
+	for _, s := range sessions {
+		byTable[s.TableID] = append(byTable[s.TableID], s)
+	}
+	var charges []charge
+	for tableID, tableSessions := range byTable {
+		slices.SortFunc(tableSessions, func(a, b *models.Session) int {
+			return a.Start.Compare(b.Start)
+		})
+		var used, billed time.Duration
+		for _, s := range tableSessions {
+			for billed < used+s.Duration() {
+				charges = append(charges, charge{
+					at:  s.PlayedAt(billed - used),
+					sum: rate(tableID),
+				})
+				billed += time.Hour
+			}
+			used += s.Duration()
+		}
+	}
+	return charges
+}
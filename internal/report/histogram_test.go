@@ -0,0 +1,43 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+func TestNewHistogram(t *testing.T) {
+	sessions := []*models.Session{
+		{TableID: 1, ClientName: "a", Start: parse(t, "10:30"), End: parse(t, "11:00")},
+		{TableID: 1, ClientName: "b", Start: parse(t, "11:00"), End: parse(t, "12:10")},
+		{TableID: 2, ClientName: "c", Start: parse(t, "10:45"), End: parse(t, "11:15")},
+	}
+	waits := []*models.Wait{
+		{ClientName: "b", Start: parse(t, "10:50"), End: parse(t, "11:00"), Outcome: models.WaitSeated},
+	}
+	rate := func(tableID int) models.Money {
+		return 1000
+	}
+
+	h := NewHistogram(parse(t, "10:00"), parse(t, "12:30"), time.Hour, sessions, waits, rate)
+
+	expected := []Bucket{
+		{Start: parse(t, "10:00"), End: parse(t, "11:00"), Occupied: 0.75, PeakOccupied: 2, PeakQueue: 1, Revenue: 2000},
+		{Start: parse(t, "11:00"), End: parse(t, "12:00"), Occupied: 1.25, PeakOccupied: 2, PeakQueue: 0, Revenue: 1000},
+		{Start: parse(t, "12:00"), End: parse(t, "12:30"), Occupied: 1.0 / 3, PeakOccupied: 1, PeakQueue: 0, Revenue: 0},
+	}
+	if len(h.Buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d", len(expected), len(h.Buckets))
+	}
+	var total models.Money
+	for i, b := range h.Buckets {
+		if *b != expected[i] {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, expected[i], *b)
+		}
+		total += b.Revenue
+	}
+	if total != 2*1000+1000 {
+		t.Errorf("Expected bucket revenue to add up to the day revenue 30, got %s", total)
+	}
+}
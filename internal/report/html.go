@@ -0,0 +1,235 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+const (
+	htmlLabelWidth = 90
+	htmlChartWidth = 960
+	htmlRowHeight  = 28
+	htmlAxisHeight = 24
+)
+
+type htmlPage struct {
+	Opening string
+	Closing string
+	Width   int
+	Height  int
+	Ticks   []htmlTick
+	Rows    []htmlRow
+	Bars    []htmlBar
+	Markers []htmlMarker
+	Profits []htmlProfit
+	Total   models.Money
+}
+
+type htmlTick struct {
+	X     int
+	Label string
+}
+
+type htmlRow struct {
+	Y     int
+	Label string
+}
+
+type htmlBar struct {
+	X, Y, W int
+	Label   string
+	Title   string
+	Class   string
+}
+
+type htmlMarker struct {
+	X, Y  int
+	Title string
+	Class string
+}
+
+type htmlProfit struct {
+	Table    int
+	Revenue  models.Money
+	Occupied string
+}
+
+// WriteHTML renders a standalone page with one timeline row per table,
+// queue waits in lanes of their own and a row of error markers, followed by
+// the per-table profit summary.
+func WriteHTML(w io.Writer, d *Day) error {
+	start, end := d.Opening, d.Closing
+	for _, e := range d.Events {
+		if e.Timestamp.Before(start) {
+			start = e.Timestamp
+		}
+		if e.Timestamp.After(end) {
+			end = e.Timestamp
+		}
+	}
+	span := end.Sub(start)
+	x := func(t time.Time) int {
+		if span <= 0 {
+			return htmlLabelWidth
+		}
+		return htmlLabelWidth + int(float64(t.Sub(start))/float64(span)*htmlChartWidth)
+	}
+
+	page := &htmlPage{
+		Opening: d.Time.Format(d.Opening),
+		Closing: d.Time.Format(d.Closing),
+		Width:   htmlLabelWidth + htmlChartWidth + 10,
+	}
+	for t := start.Truncate(time.Hour); !t.After(end); t = t.Add(time.Hour) {
+		if !t.Before(start) {
+			page.Ticks = append(page.Ticks, htmlTick{X: x(t), Label: d.Time.Format(t)})
+		}
+	}
+
+	rowY := make(map[int]int)
+	y := htmlAxisHeight
+	for _, p := range d.Profits {
+		rowY[p.Table.Id] = y
+		page.Rows = append(page.Rows, htmlRow{Y: y, Label: fmt.Sprintf("Table %d", p.Table.Id)})
+		y += htmlRowHeight
+	}
+	for _, s := range d.Sessions {
+		page.Bars = append(page.Bars, htmlBar{
+			X:     x(s.Start),
+			Y:     rowY[s.TableID],
+			W:     max(x(s.End)-x(s.Start), 1),
+			Label: s.ClientName,
+			Title: fmt.Sprintf("%s table %d %s-%s", s.ClientName, s.TableID, d.Time.Format(s.Start), d.Time.Format(s.End)),
+			Class: "session",
+		})
+	}
+
+	waits := slices.Clone(d.Waits)
+	slices.SortFunc(waits, func(a, b *models.Wait) int {
+		return a.Start.Compare(b.Start)
+	})
+	var laneEnds []time.Time
+	queueY := y
+	for _, wait := range waits {
+		lane := slices.IndexFunc(laneEnds, func(t time.Time) bool {
+			return !t.After(wait.Start)
+		})
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+		}
+		laneEnds[lane] = wait.End
+		page.Bars = append(page.Bars, htmlBar{
+			X:     x(wait.Start),
+			Y:     queueY + lane*htmlRowHeight,
+			W:     max(x(wait.End)-x(wait.Start), 1),
+			Label: wait.ClientName,
+			Title: fmt.Sprintf("%s waiting %s-%s", wait.ClientName, d.Time.Format(wait.Start), d.Time.Format(wait.End)),
+			Class: "wait",
+		})
+	}
+	for lane := 0; lane < max(len(laneEnds), 1); lane++ {
+		page.Rows = append(page.Rows, htmlRow{Y: y, Label: "Queue"})
+		y += htmlRowHeight
+	}
+
+	eventsY := y
+	page.Rows = append(page.Rows, htmlRow{Y: eventsY, Label: "Events"})
+	y += htmlRowHeight
+
+	var last string
+	for _, e := range d.Events {
+		switch e.Code {
+		case models.EventError:
+			page.Markers = append(page.Markers, htmlMarker{
+				X:     x(e.Timestamp),
+				Y:     eventsY,
+				Title: fmt.Sprintf("%s %s %s", d.Time.Format(e.Timestamp), e.ErrorMsg, last),
+				Class: "error",
+			})
+		case models.ClientForceLeft:
+			markerY := eventsY
+			for _, s := range d.Sessions {
+				if s.ClientName == e.ClientName && s.End.Equal(e.Timestamp) {
+					markerY = rowY[s.TableID]
+				}
+			}
+			page.Markers = append(page.Markers, htmlMarker{
+				X:     x(e.Timestamp),
+				Y:     markerY,
+				Title: fmt.Sprintf("%s %s forced to leave", d.Time.Format(e.Timestamp), e.ClientName),
+				Class: "forced",
+			})
+		}
+		if e.Code != models.EventError {
+			last = e.ClientName
+		}
+	}
+	page.Height = y + 4
+
+	for _, p := range d.Profits {
+		page.Profits = append(page.Profits, htmlProfit{
+			Table:    p.Table.Id,
+			Revenue:  p.Sum,
+			Occupied: d.Time.FormatDuration(p.Table.TotalTime),
+		})
+		page.Total += p.Sum
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Club report {{.Opening}}-{{.Closing}}</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+svg text { font-size: 11px; }
+.axis { stroke: #ccc; }
+.row { fill: #f6f6f6; }
+.session { fill: #4a90d9; }
+.wait { fill: #f0ad4e; }
+.error { fill: #d9534f; }
+.forced { fill: #333; }
+.label { fill: #fff; }
+table { border-collapse: collapse; margin-top: 20px; }
+td, th { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+</style>
+</head>
+<body>
+<h1>Club report {{.Opening}}-{{.Closing}}</h1>
+<svg width="{{.Width}}" height="{{.Height}}" xmlns="http://www.w3.org/2000/svg">
+{{- range .Rows}}
+<rect class="row" x="0" y="{{.Y}}" width="100%" height="26"/>
+<text x="4" y="{{.Y}}" dy="17">{{.Label}}</text>
+{{- end}}
+{{- range .Ticks}}
+<line class="axis" x1="{{.X}}" y1="16" x2="{{.X}}" y2="{{$.Height}}"/>
+<text x="{{.X}}" y="12" text-anchor="middle">{{.Label}}</text>
+{{- end}}
+{{- range .Bars}}
+<g><title>{{.Title}}</title>
+<rect class="{{.Class}}" x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="26" rx="3"/>
+<text class="label" x="{{.X}}" y="{{.Y}}" dx="3" dy="17">{{.Label}}</text></g>
+{{- end}}
+{{- range .Markers}}
+<g><title>{{.Title}}</title>
+<circle class="{{.Class}}" cx="{{.X}}" cy="{{.Y}}" r="5" transform="translate(0 13)"/></g>
+{{- end}}
+</svg>
+<table>
+<tr><th>Table</th><th>Revenue</th><th>Occupied</th></tr>
+{{- range .Profits}}
+<tr><td>{{.Table}}</td><td>{{.Revenue}}</td><td>{{.Occupied}}</td></tr>
+{{- end}}
+<tr><th>Total</th><th>{{.Total}}</th><th></th></tr>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+)
+
+func TestWriteHTML(t *testing.T) {
+	table := &models.Table{Id: 1, TotalTime: 90 * time.Minute}
+	day := &Day{
+		Opening: parse(t, "10:00"),
+		Closing: parse(t, "12:00"),
+		Events: []*models.Event{
+			{Code: models.ClientArrived, Timestamp: parse(t, "09:50"), ClientName: "early"},
+			{Code: models.EventError, Timestamp: parse(t, "09:50"), ErrorMsg: service.ErrNotOpenYet},
+			{Code: models.ClientForceLeft, Timestamp: parse(t, "12:00"), ClientName: "late"},
+		},
+		Profits: []*models.Profit{{Table: table, Sum: 2000}},
+		Sessions: []*models.Session{
+			{TableID: 1, ClientName: "late", Start: parse(t, "10:30"), End: parse(t, "12:00")},
+		},
+		Waits: []*models.Wait{
+			{ClientName: "late", Start: parse(t, "10:20"), End: parse(t, "10:30"), Outcome: models.WaitSeated},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, day); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		"late table 1 10:30-12:00",
+		"late waiting 10:20-10:30",
+		"09:50 NotOpenYet early",
+		"12:00 late forced to leave",
+		"<tr><td>1</td><td>20</td><td>01:30</td></tr>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q", want)
+		}
+	}
+	for _, external := range []string{"<script src", "<link", "@import"} {
+		if strings.Contains(page, external) {
+			t.Errorf("Expected no external assets, found %q", external)
+		}
+	}
+}
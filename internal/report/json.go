@@ -0,0 +1,360 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+type jsonDay struct {
+	Opening   string          `json:"opening"`
+	Events    []*jsonEvent    `json:"events"`
+	Closing   string          `json:"closing"`
+	Profits   []*jsonProfit   `json:"profits"`
+	Downtime  []*jsonDowntime `json:"downtime,omitempty"`
+	Revenue   *jsonRevenue    `json:"revenue,omitempty"`
+	Tax       *jsonTax        `json:"tax,omitempty"`
+	Stats     *jsonStats      `json:"stats,omitempty"`
+	Clients   []*jsonClient   `json:"clients,omitempty"`
+	Histogram *jsonHistogram  `json:"histogram,omitempty"`
+
+	Corrections []*jsonCorrection `json:"corrections,omitempty"`
+}
+
+type jsonCorrection struct {
+	Seq      int        `json:"seq"`
+	Time     string     `json:"time"`
+	Ref      int        `json:"ref"`
+	Original *jsonEvent `json:"original"`
+	Amended  *jsonEvent `json:"amended,omitempty"`
+}
+
+type jsonHistogram struct {
+	Bucket  string        `json:"bucket"`
+	Buckets []*jsonBucket `json:"buckets"`
+}
+
+type jsonBucket struct {
+	Start        string       `json:"start"`
+	End          string       `json:"end"`
+	Occupied     float64      `json:"occupied"`
+	PeakOccupied int          `json:"peak_occupied"`
+	PeakQueue    int          `json:"peak_queue"`
+	Revenue      models.Money `json:"revenue"`
+}
+
+type jsonEvent struct {
+	Time   string `json:"time"`
+	Code   int    `json:"code"`
+	Client string `json:"client,omitempty"`
+	Table  int    `json:"table,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	Ref   int        `json:"ref,omitempty"`
+	Amend *jsonEvent `json:"amend,omitempty"`
+}
+
+type jsonProfit struct {
+	Table    int          `json:"table"`
+	Revenue  models.Money `json:"revenue"`
+	Occupied string       `json:"occupied"`
+}
+
+type jsonRevenue struct {
+	Tables    []*jsonTableRevenue `json:"tables"`
+	Unseated  models.Money        `json:"unseated_extras"`
+	Table     models.Money        `json:"table"`
+	Extras    models.Money        `json:"extras"`
+	Discounts models.Money        `json:"discounts"`
+	Total     models.Money        `json:"total"`
+}
+
+type jsonTax struct {
+	Rate     models.Percent  `json:"rate"`
+	Included bool            `json:"included"`
+	Rounding string          `json:"rounding"`
+	Currency string          `json:"currency,omitempty"`
+	Tables   []*jsonTaxSplit `json:"tables"`
+	Unseated *jsonTaxSplit   `json:"unseated_extras,omitempty"`
+	Total    *jsonTaxSplit   `json:"total"`
+}
+
+type jsonTaxSplit struct {
+	Table int          `json:"table,omitempty"`
+	Net   models.Money `json:"net"`
+	Tax   models.Money `json:"tax"`
+	Gross models.Money `json:"gross"`
+}
+
+type jsonTableRevenue struct {
+	Table     int          `json:"table"`
+	Time      models.Money `json:"time"`
+	Extras    models.Money `json:"extras"`
+	Discounts models.Money `json:"discounts"`
+	Total     models.Money `json:"total"`
+}
+
+type jsonExtra struct {
+	Time     string       `json:"time"`
+	Item     string       `json:"item"`
+	Price    models.Money `json:"price"`
+	Quantity int          `json:"quantity"`
+	Table    int          `json:"table,omitempty"`
+	Charge   models.Money `json:"charge"`
+}
+
+type jsonDowntime struct {
+	Table    int    `json:"table"`
+	Downtime string `json:"downtime"`
+}
+
+type jsonClient struct {
+	Name   string       `json:"name"`
+	Visits []*jsonVisit `json:"visits"`
+	Errors []*jsonEvent `json:"errors"`
+	Played string       `json:"played"`
+	Waited string       `json:"waited"`
+	Total  models.Money `json:"total"`
+}
+
+type jsonVisit struct {
+	Arrived  string         `json:"arrived"`
+	Left     string         `json:"left"`
+	Forced   bool           `json:"forced"`
+	Waits    []*jsonWait    `json:"waits"`
+	Sessions []*jsonSession `json:"sessions"`
+	Extras   []*jsonExtra   `json:"extras,omitempty"`
+}
+
+type jsonWait struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Duration string `json:"duration"`
+}
+
+type jsonSession struct {
+	Table    int          `json:"table"`
+	Start    string       `json:"start"`
+	End      string       `json:"end"`
+	Duration string       `json:"duration"`
+	Hours    int          `json:"hours"`
+	Charge   models.Money `json:"charge"`
+}
+
+type jsonStats struct {
+	Tables        []*jsonTableStats `json:"tables"`
+	Queue         *jsonQueueStats   `json:"queue"`
+	PeakOccupancy int               `json:"peak_occupancy"`
+	PeakTime      string            `json:"peak_time,omitempty"`
+	Errors        map[string]int    `json:"errors"`
+}
+
+type jsonTableStats struct {
+	Table       int     `json:"table"`
+	Sessions    int     `json:"sessions"`
+	Occupied    string  `json:"occupied"`
+	Utilization float64 `json:"utilization"`
+}
+
+type jsonQueueStats struct {
+	Waited           int    `json:"waited"`
+	AverageWait      string `json:"average_wait"`
+	MaxWait          string `json:"max_wait"`
+	GaveUp           int    `json:"gave_up"`
+	LeftWhileWaiting int    `json:"left_while_waiting"`
+	UnservedAtClose  int    `json:"unserved_at_close"`
+}
+
+func writeJSON(w io.Writer, d *Day) error {
+	out := &jsonDay{
+		Opening: d.Time.Format(d.Opening),
+		Events:  make([]*jsonEvent, 0, len(d.Events)),
+		Closing: d.Time.Format(d.Closing),
+		Profits: make([]*jsonProfit, 0, len(d.Profits)),
+	}
+	for _, e := range d.Events {
+		out.Events = append(out.Events, newJSONEvent(e, d.Time))
+	}
+	for _, p := range d.Profits {
+		out.Profits = append(out.Profits, &jsonProfit{
+			Table:    p.Table.Id,
+			Revenue:  p.Sum,
+			Occupied: d.Time.FormatDuration(p.Table.TotalTime),
+		})
+	}
+	for _, p := range d.Profits {
+		if p.Table.Downtime > 0 {
+			out.Downtime = append(out.Downtime, &jsonDowntime{
+				Table:    p.Table.Id,
+				Downtime: d.Time.FormatDuration(p.Table.Downtime),
+			})
+		}
+	}
+	if r := d.Revenue; r != nil {
+		out.Revenue = &jsonRevenue{Unseated: r.Unseated, Table: r.Table, Extras: r.Extras,
+			Discounts: r.Discounts, Total: r.Total}
+		for _, t := range r.Tables {
+			out.Revenue.Tables = append(out.Revenue.Tables, &jsonTableRevenue{
+				Table:     t.TableID,
+				Time:      t.Table,
+				Extras:    t.Extras,
+				Discounts: t.Discount,
+				Total:     t.Total,
+			})
+		}
+	}
+	if t := d.Tax; t != nil {
+		out.Tax = &jsonTax{
+			Rate:     t.Tax.Rate,
+			Included: t.Tax.Included,
+			Rounding: models.RoundingName(t.Tax.Rounding),
+			Currency: t.Currency,
+			Total:    newJSONTaxSplit(0, t.Total),
+		}
+		for _, row := range t.Tables {
+			out.Tax.Tables = append(out.Tax.Tables, newJSONTaxSplit(row.TableID, row.TaxSplit))
+		}
+		if t.Unseated != nil {
+			out.Tax.Unseated = newJSONTaxSplit(0, *t.Unseated)
+		}
+	}
+	if d.Stats != nil {
+		out.Stats = newJSONStats(d.Stats, d.Time)
+	}
+	for _, c := range d.Clients {
+		out.Clients = append(out.Clients, newJSONClient(c, d.Time))
+	}
+	if d.Histogram != nil {
+		out.Histogram = &jsonHistogram{Bucket: d.Histogram.Size.String()}
+		for _, b := range d.Histogram.Buckets {
+			out.Histogram.Buckets = append(out.Histogram.Buckets, &jsonBucket{
+				Start:        d.Time.Format(b.Start),
+				End:          d.Time.Format(b.End),
+				Occupied:     math.Round(b.Occupied*100) / 100,
+				PeakOccupied: b.PeakOccupied,
+				PeakQueue:    b.PeakQueue,
+				Revenue:      b.Revenue,
+			})
+		}
+	}
+	for _, c := range d.Corrections {
+		jc := &jsonCorrection{
+			Seq:      c.Seq,
+			Time:     d.Time.Format(c.Timestamp),
+			Ref:      c.Ref,
+			Original: newJSONEvent(c.Original, d.Time),
+		}
+		if c.Amended != nil {
+			jc.Amended = newJSONEvent(c.Amended, d.Time)
+		}
+		out.Corrections = append(out.Corrections, jc)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func newJSONEvent(e *models.Event, tf utils.TimeFormat) *jsonEvent {
+	je := &jsonEvent{
+		Time:   tf.Format(e.Timestamp),
+		Code:   e.Code,
+		Client: e.ClientName,
+		Table:  e.TableID,
+	}
+	if e.ErrorMsg != nil {
+		je.Error = e.ErrorMsg.Error()
+	}
+	if e.Code == models.EventCorrection {
+		je.Ref = e.Ref
+		if e.Amend != nil {
+			je.Amend = newJSONEvent(e.Amend, tf)
+		}
+	}
+	return je
+}
+
+func newJSONTaxSplit(tableID int, s models.TaxSplit) *jsonTaxSplit {
+	return &jsonTaxSplit{Table: tableID, Net: s.Net, Tax: s.Tax, Gross: s.Gross}
+}
+
+func newJSONStats(s *Stats, tf utils.TimeFormat) *jsonStats {
+	out := &jsonStats{
+		Queue: &jsonQueueStats{
+			Waited:           s.Queue.Waited,
+			AverageWait:      tf.FormatDuration(s.Queue.AverageWait),
+			MaxWait:          tf.FormatDuration(s.Queue.MaxWait),
+			GaveUp:           s.Queue.GaveUp,
+			LeftWhileWaiting: s.Queue.LeftWhileWaiting,
+			UnservedAtClose:  s.Queue.UnservedAtClose,
+		},
+		PeakOccupancy: s.PeakOccupancy,
+		Errors:        s.Errors,
+	}
+	if s.PeakOccupancy > 0 {
+		out.PeakTime = tf.Format(s.PeakTime)
+	}
+	for _, t := range s.Tables {
+		out.Tables = append(out.Tables, &jsonTableStats{
+			Table:       t.ID,
+			Sessions:    t.Sessions,
+			Occupied:    tf.FormatDuration(t.Occupied),
+			Utilization: math.Round(t.Utilization*10) / 10,
+		})
+	}
+	return out
+}
+
+func newJSONClient(c *ClientSummary, tf utils.TimeFormat) *jsonClient {
+	out := &jsonClient{
+		Name:   c.Name,
+		Visits: make([]*jsonVisit, 0, len(c.Visits)),
+		Errors: make([]*jsonEvent, 0, len(c.Errors)),
+		Played: tf.FormatDuration(c.Played),
+		Waited: tf.FormatDuration(c.Waited),
+		Total:  c.Total,
+	}
+	for _, v := range c.Visits {
+		jv := &jsonVisit{
+			Arrived:  tf.Format(v.Arrived),
+			Left:     tf.Format(v.Left),
+			Forced:   v.Forced,
+			Waits:    make([]*jsonWait, 0, len(v.Waits)),
+			Sessions: make([]*jsonSession, 0, len(v.Sessions)),
+		}
+		for _, w := range v.Waits {
+			jv.Waits = append(jv.Waits, &jsonWait{
+				Start:    tf.Format(w.Start),
+				End:      tf.Format(w.End),
+				Duration: tf.FormatDuration(w.Duration()),
+			})
+		}
+		for _, s := range v.Sessions {
+			jv.Sessions = append(jv.Sessions, &jsonSession{
+				Table:    s.TableID,
+				Start:    tf.Format(s.Start),
+				End:      tf.Format(s.End),
+				Duration: tf.FormatDuration(s.Duration()),
+				Hours:    s.Hours,
+				Charge:   s.Charge,
+			})
+		}
+		for _, e := range v.Extras {
+			jv.Extras = append(jv.Extras, &jsonExtra{
+				Time:     tf.Format(e.Timestamp),
+				Item:     e.Item,
+				Price:    e.Price,
+				Quantity: e.Quantity,
+				Table:    e.TableID,
+				Charge:   e.Sum(),
+			})
+		}
+		out.Visits = append(out.Visits, jv)
+	}
+	for _, e := range c.Errors {
+		out.Errors = append(out.Errors, newJSONEvent(e, tf))
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"github.com/Korpenter/club/internal/models"
+)
+
+// Revenue splits the day's revenue into table time and extras, less the
+// discounts given on table time, so its total is the sum of the day's
+// receipts. Extras ordered by a client who was not seated are not attributed
+// to any table.
+type Revenue struct {
+	Tables    []*TableRevenue
+	Unseated  models.Money
+	Table     models.Money
+	Extras    models.Money
+	Discounts models.Money
+	Total     models.Money
+}
+
+type TableRevenue struct {
+	TableID  int
+	Table    models.Money
+	Extras   models.Money
+	Discount models.Money
+	Total    models.Money
+}
+
+// NewRevenue takes discounts by table id.
+func NewRevenue(profits []*models.Profit, extras []*models.Extra, discounts map[int]models.Money) *Revenue {
+	r := &Revenue{}
+	byTable := make(map[int]*TableRevenue, len(profits))
+	for _, p := range profits {
+		t := &TableRevenue{TableID: p.Table.Id, Table: p.Sum, Discount: discounts[p.Table.Id]}
+		byTable[t.TableID] = t
+		r.Tables = append(r.Tables, t)
+		r.Table += p.Sum
+		r.Discounts += t.Discount
+	}
+	for _, e := range extras {
+		if t, ok := byTable[e.TableID]; ok {
+			t.Extras += e.Sum()
+		} else {
+			r.Unseated += e.Sum()
+		}
+		r.Extras += e.Sum()
+	}
+	for _, t := range r.Tables {
+		t.Total = t.Table + t.Extras - t.Discount
+	}
+	r.Total = r.Table + r.Extras - r.Discounts
+	return r
+}
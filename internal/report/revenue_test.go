@@ -0,0 +1,35 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+func TestNewRevenue(t *testing.T) {
+	profits := []*models.Profit{
+		{Table: &models.Table{Id: 1}, Sum: 20},
+		{Table: &models.Table{Id: 2}, Sum: 30},
+	}
+	extras := []*models.Extra{
+		{ClientName: "alice", Item: "cola", Price: 50, Quantity: 2},
+		{ClientName: "alice", Item: "chips", Price: 30, Quantity: 1, TableID: 1},
+		{ClientName: "bob", Item: "tea", Price: 15, Quantity: 3, TableID: 2},
+	}
+	got := NewRevenue(profits, extras, map[int]models.Money{1: 5})
+	want := &Revenue{
+		Tables: []*TableRevenue{
+			{TableID: 1, Table: 20, Extras: 30, Discount: 5, Total: 45},
+			{TableID: 2, Table: 30, Extras: 45, Total: 75},
+		},
+		Unseated:  100,
+		Table:     50,
+		Extras:    175,
+		Discounts: 5,
+		Total:     220,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
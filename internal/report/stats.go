@@ -0,0 +1,120 @@
+package report
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+type Stats struct {
+	Tables        []*TableStats
+	Queue         QueueStats
+	PeakOccupancy int
+	PeakTime      time.Time
+	Errors        map[string]int
+}
+
+type TableStats struct {
+	ID          int
+	Sessions    int
+	Occupied    time.Duration
+	Utilization float64
+}
+
+type QueueStats struct {
+	Waited           int
+	AverageWait      time.Duration
+	MaxWait          time.Duration
+	GaveUp           int
+	LeftWhileWaiting int
+	UnservedAtClose  int
+}
+
+// NewStats summarizes a finished day. Events must not include the
+// departures forced at closing, those are covered by the waits.
+func NewStats(opening, closing time.Time, tables []*models.Table, sessions []*models.Session,
+	waits []*models.Wait, events []*models.Event) *Stats {
+	stats := &Stats{
+		Errors: make(map[string]int),
+	}
+
+	open := closing.Sub(opening)
+	for _, t := range tables {
+		ts := &TableStats{
+			ID:       t.Id,
+			Occupied: t.TotalTime,
+		}
+		for _, s := range sessions {
+			if s.TableID == t.Id {
+				ts.Sessions++
+			}
+		}
+		if open > 0 {
+			ts.Utilization = float64(ts.Occupied) / float64(open) * 100
+		}
+		stats.Tables = append(stats.Tables, ts)
+	}
+	slices.SortFunc(stats.Tables, func(a, b *TableStats) int {
+		return a.ID - b.ID
+	})
+
+	var total time.Duration
+	for _, w := range waits {
+		stats.Queue.Waited++
+		total += w.Duration()
+		stats.Queue.MaxWait = max(stats.Queue.MaxWait, w.Duration())
+		switch w.Outcome {
+		case models.WaitLeft:
+			stats.Queue.LeftWhileWaiting++
+		case models.WaitClosed:
+			stats.Queue.UnservedAtClose++
+		}
+	}
+	if stats.Queue.Waited > 0 {
+		stats.Queue.AverageWait = total / time.Duration(stats.Queue.Waited)
+	}
+
+	for _, e := range events {
+		switch e.Code {
+		case models.ClientForceLeft:
+			stats.Queue.GaveUp++
+		case models.EventError:
+			stats.Errors[e.ErrorMsg.Error()]++
+		}
+	}
+
+	stats.PeakOccupancy, stats.PeakTime = peakOccupancy(sessions)
+	return stats
+}
+
+// peakOccupancy applies all arrivals and departures sharing a timestamp
+// before comparing against the peak, so a table handed over within the
+// same minute is not counted twice.
+func peakOccupancy(sessions []*models.Session) (int, time.Time) {
+	type point struct {
+		at    time.Time
+		delta int
+	}
+	points := make([]point, 0, 2*len(sessions))
+	for _, s := range sessions {
+		points = append(points, point{s.Start, 1}, point{s.End, -1})
+	}
+	slices.SortFunc(points, func(a, b point) int {
+		return a.at.Compare(b.at)
+	})
+
+	var current, peak int
+	var peakTime time.Time
+	for i, p := range points {
+		current += p.delta
+		if i+1 < len(points) && points[i+1].at.Equal(p.at) {
+			continue
+		}
+		if current > peak {
+			peak = current
+			peakTime = p.at
+		}
+	}
+	return peak, peakTime
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+func parse(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := utils.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestNewStats(t *testing.T) {
+	opening, closing := parse(t, "10:00"), parse(t, "20:00")
+	tables := []*models.Table{
+		{Id: 2, TotalTime: 2 * time.Hour},
+		{Id: 1, TotalTime: 5 * time.Hour},
+	}
+	sessions := []*models.Session{
+		{TableID: 1, ClientName: "a", Start: parse(t, "10:00"), End: parse(t, "12:00")},
+		{TableID: 2, ClientName: "b", Start: parse(t, "11:00"), End: parse(t, "13:00")},
+		{TableID: 1, ClientName: "c", Start: parse(t, "12:00"), End: parse(t, "15:00")},
+	}
+	waits := []*models.Wait{
+		{ClientName: "c", Start: parse(t, "11:30"), End: parse(t, "12:00"), Outcome: models.WaitSeated},
+		{ClientName: "d", Start: parse(t, "11:40"), End: parse(t, "12:30"), Outcome: models.WaitLeft},
+		{ClientName: "e", Start: parse(t, "19:50"), End: parse(t, "20:00"), Outcome: models.WaitClosed},
+	}
+	events := []*models.Event{
+		{Code: models.EventError, Timestamp: parse(t, "09:00"), ErrorMsg: service.ErrNotOpenYet},
+		{Code: models.EventError, Timestamp: parse(t, "11:00"), ErrorMsg: service.ErrPlaceIsBusy},
+		{Code: models.EventError, Timestamp: parse(t, "11:05"), ErrorMsg: service.ErrPlaceIsBusy},
+		{Code: models.ClientForceLeft, Timestamp: parse(t, "11:45"), ClientName: "f"},
+	}
+
+	stats := NewStats(opening, closing, tables, sessions, waits, events)
+
+	if len(stats.Tables) != 2 || stats.Tables[0].ID != 1 || stats.Tables[0].Sessions != 2 || stats.Tables[0].Utilization != 50 {
+		t.Errorf("Unexpected table stats: %+v", stats.Tables[0])
+	}
+	if stats.Tables[1].Sessions != 1 || stats.Tables[1].Utilization != 20 {
+		t.Errorf("Unexpected table stats: %+v", stats.Tables[1])
+	}
+	expectedQueue := QueueStats{
+		Waited:           3,
+		AverageWait:      30 * time.Minute,
+		MaxWait:          50 * time.Minute,
+		GaveUp:           1,
+		LeftWhileWaiting: 1,
+		UnservedAtClose:  1,
+	}
+	if stats.Queue != expectedQueue {
+		t.Errorf("Expected queue stats %+v, got %+v", expectedQueue, stats.Queue)
+	}
+	if stats.PeakOccupancy != 2 || !stats.PeakTime.Equal(parse(t, "11:00")) {
+		t.Errorf("Expected peak 2 at 11:00, got %d at %s", stats.PeakOccupancy, utils.Format(stats.PeakTime))
+	}
+	if stats.Errors["PlaceIsBusy"] != 2 || stats.Errors["NotOpenYet"] != 1 {
+		t.Errorf("Unexpected error counts: %v", stats.Errors)
+	}
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"github.com/Korpenter/club/internal/models"
+)
+
+// Taxes splits what each table took, extras included, into net, tax and
+// gross. The tax is rounded per table and the total is the sum of the
+// rounded rows, so the section adds up.
+type Taxes struct {
+	Tax      models.Tax
+	Currency string
+	Tables   []*TableTax
+	Unseated *models.TaxSplit
+	Total    models.TaxSplit
+}
+
+type TableTax struct {
+	TableID int
+	models.TaxSplit
+}
+
+func NewTaxes(tax models.Tax, currency string, r *Revenue) *Taxes {
+	t := &Taxes{Tax: tax, Currency: currency}
+	for _, tr := range r.Tables {
+		row := &TableTax{TableID: tr.TableID, TaxSplit: tax.Split(tr.Total)}
+		t.Tables = append(t.Tables, row)
+		t.Total = t.Total.Add(row.TaxSplit)
+	}
+	if r.Unseated > 0 {
+		split := tax.Split(r.Unseated)
+		t.Unseated = &split
+		t.Total = t.Total.Add(split)
+	}
+	return t
+}
@@ -0,0 +1,44 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Korpenter/club/internal/models"
+)
+
+func TestNewTaxes(t *testing.T) {
+	revenue := &Revenue{
+		Tables: []*TableRevenue{
+			{TableID: 1, Total: 14950},
+			{TableID: 2, Total: 14950},
+		},
+		Unseated: 600,
+	}
+	taxes := NewTaxes(models.Tax{Rate: 2000, Included: true}, "RUB", revenue)
+
+	var buf bytes.Buffer
+	writeTaxText(&buf, taxes)
+	want := `Tax (20% included, RUB):
+Table 1: net 124.58, tax 24.92, gross 149.50
+Table 2: net 124.58, tax 24.92, gross 149.50
+No table: net 5.00, tax 1.00, gross 6.00
+Total: net 254.16, tax 50.84, gross 305.00
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, &Day{Tax: taxes}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"total": {
+      "net": 254.16,
+      "tax": 50.84,
+      "gross": 305
+    }`) {
+		t.Errorf("unexpected JSON:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,212 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+func ValidFormat(format string) bool {
+	switch format {
+	case "", FormatText, FormatJSON, FormatCSV:
+		return true
+	}
+	return false
+}
+
+func Write(w io.Writer, format string, d *Day) error {
+	switch format {
+	case "", FormatText:
+		return writeText(w, d)
+	case FormatJSON:
+		return writeJSON(w, d)
+	case FormatCSV:
+		return writeCSV(w, d)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func writeText(w io.Writer, d *Day) error {
+	events := d.Events
+	if d.Streamed {
+		events = events[d.StreamedEvents:]
+	} else {
+		fmt.Fprintln(w, d.Time.Format(d.Opening))
+	}
+	for _, v := range events {
+		fmt.Fprintln(w, v.Format(d.Time))
+	}
+	fmt.Fprintln(w, d.Time.Format(d.Closing))
+	for _, v := range d.Profits {
+		fmt.Fprintln(w, v.Format(d.Time))
+	}
+	if hasDowntime(d.Profits) {
+		fmt.Fprintln(w, "Downtime:")
+		for _, p := range d.Profits {
+			if p.Table.Downtime > 0 {
+				fmt.Fprintf(w, "Table %d: %s\n", p.Table.Id, d.Time.FormatDuration(p.Table.Downtime))
+			}
+		}
+	}
+	if d.Revenue != nil {
+		writeRevenueText(w, d.Revenue)
+	}
+	if d.Tax != nil {
+		writeTaxText(w, d.Tax)
+	}
+	if d.Stats != nil {
+		writeStatsText(w, d.Stats, d.Time)
+	}
+	if d.Clients != nil {
+		writeClientsText(w, d.Clients, d.Time)
+	}
+	if d.Histogram != nil {
+		writeHistogramText(w, d.Histogram, d.Time)
+	}
+	if len(d.Corrections) > 0 {
+		writeCorrectionsText(w, d.Corrections, d.Time)
+	}
+	return nil
+}
+
+func writeCorrectionsText(w io.Writer, corrections []*models.Correction, tf utils.TimeFormat) {
+	fmt.Fprintln(w, "Corrections:")
+	for _, c := range corrections {
+		if c.Amended == nil {
+			fmt.Fprintf(w, "#%d %s voided #%d: %s\n", c.Seq, tf.Format(c.Timestamp), c.Ref, c.Original.Format(tf))
+		} else {
+			fmt.Fprintf(w, "#%d %s amended #%d: %s -> %s\n", c.Seq, tf.Format(c.Timestamp), c.Ref, c.Original.Format(tf), c.Amended.Format(tf))
+		}
+	}
+}
+
+func writeRevenueText(w io.Writer, r *Revenue) {
+	fmt.Fprintln(w, "Revenue:")
+	// Discounts are only listed on days that have any.
+	for _, t := range r.Tables {
+		fmt.Fprintf(w, "Table %d: tables %s, extras %s, ", t.TableID, t.Table, t.Extras)
+		if r.Discounts > 0 {
+			fmt.Fprintf(w, "discounts %s, ", t.Discount)
+		}
+		fmt.Fprintf(w, "total %s\n", t.Total)
+	}
+	if r.Unseated > 0 {
+		fmt.Fprintf(w, "No table: extras %s\n", r.Unseated)
+	}
+	fmt.Fprintf(w, "Total: tables %s, extras %s, ", r.Table, r.Extras)
+	if r.Discounts > 0 {
+		fmt.Fprintf(w, "discounts %s, ", r.Discounts)
+	}
+	fmt.Fprintf(w, "total %s\n", r.Total)
+}
+
+func writeTaxText(w io.Writer, t *Taxes) {
+	mode := "added"
+	if t.Tax.Included {
+		mode = "included"
+	}
+	heading := fmt.Sprintf("%s%% %s", t.Tax.Rate, mode)
+	if t.Currency != "" {
+		heading += ", " + t.Currency
+	}
+	fmt.Fprintf(w, "Tax (%s):\n", heading)
+	for _, row := range t.Tables {
+		fmt.Fprintf(w, "Table %d: %s\n", row.TableID, formatTaxSplit(row.TaxSplit))
+	}
+	if t.Unseated != nil {
+		fmt.Fprintf(w, "No table: %s\n", formatTaxSplit(*t.Unseated))
+	}
+	fmt.Fprintf(w, "Total: %s\n", formatTaxSplit(t.Total))
+}
+
+func formatTaxSplit(s models.TaxSplit) string {
+	return fmt.Sprintf("net %s, tax %s, gross %s", s.Net.Decimal(), s.Tax.Decimal(), s.Gross.Decimal())
+}
+
+func writeStatsText(w io.Writer, s *Stats, tf utils.TimeFormat) {
+	fmt.Fprintln(w, "Stats:")
+	for _, t := range s.Tables {
+		fmt.Fprintf(w, "Table %d: %d sessions, occupied %s (%.1f%%)\n",
+			t.ID, t.Sessions, tf.FormatDuration(t.Occupied), t.Utilization)
+	}
+	q := s.Queue
+	fmt.Fprintf(w, "Queue: %d waited, average wait %s, max wait %s\n",
+		q.Waited, tf.FormatDuration(q.AverageWait), tf.FormatDuration(q.MaxWait))
+	fmt.Fprintf(w, "Queue: %d gave up (queue full), %d left while waiting, %d unserved at closing\n",
+		q.GaveUp, q.LeftWhileWaiting, q.UnservedAtClose)
+	if s.PeakOccupancy > 0 {
+		fmt.Fprintf(w, "Peak occupancy: %d at %s\n", s.PeakOccupancy, tf.Format(s.PeakTime))
+	} else {
+		fmt.Fprintln(w, "Peak occupancy: 0")
+	}
+	names := make([]string, 0, len(s.Errors))
+	for name := range s.Errors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	counts := make([]string, 0, len(names))
+	for _, name := range names {
+		counts = append(counts, fmt.Sprintf("%s %d", name, s.Errors[name]))
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "none")
+	}
+	fmt.Fprintf(w, "Errors: %s\n", strings.Join(counts, ", "))
+}
+
+func writeClientsText(w io.Writer, clients []*ClientSummary, tf utils.TimeFormat) {
+	fmt.Fprintln(w, "Clients:")
+	for _, c := range clients {
+		fmt.Fprintf(w, "Client %s: played %s, waited %s, total %s\n",
+			c.Name, tf.FormatDuration(c.Played), tf.FormatDuration(c.Waited), c.Total)
+		for _, v := range c.Visits {
+			left := tf.Format(v.Left)
+			if v.Forced {
+				left += " (closing)"
+			}
+			fmt.Fprintf(w, "  Visit %s-%s\n", tf.Format(v.Arrived), left)
+			for _, wait := range v.Waits {
+				fmt.Fprintf(w, "    Queue %s-%s %s\n",
+					tf.Format(wait.Start), tf.Format(wait.End), tf.FormatDuration(wait.Duration()))
+			}
+			for _, s := range v.Sessions {
+				fmt.Fprintf(w, "    Table %d %s-%s %s charge %s\n", s.TableID,
+					tf.Format(s.Start), tf.Format(s.End), tf.FormatDuration(s.Duration()), s.Charge)
+			}
+			for _, e := range v.Extras {
+				fmt.Fprintf(w, "    Extra %s %s %d x %s charge %s\n", tf.Format(e.Timestamp),
+					e.Item, e.Quantity, e.Price, e.Sum())
+			}
+		}
+		for _, e := range c.Errors {
+			fmt.Fprintf(w, "  Error %s %s\n", tf.Format(e.Timestamp), e.ErrorMsg)
+		}
+	}
+}
+
+func writeHistogramText(w io.Writer, h *Histogram, tf utils.TimeFormat) {
+	fmt.Fprintf(w, "Histogram (%s):\n", h.Size)
+	for _, b := range h.Buckets {
+		fmt.Fprintf(w, "%s-%s occupied %.2f peak %d queue %d revenue %s\n", tf.Format(b.Start),
+			tf.Format(b.End), b.Occupied, b.PeakOccupied, b.PeakQueue, b.Revenue)
+	}
+}
+
+func hasDowntime(profits []*models.Profit) bool {
+	for _, p := range profits {
+		if p.Table.Downtime > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/Korpenter/club/internal/config"
@@ -17,39 +18,71 @@ var (
 	ErrClientUnknown    = errors.New("ClientUnknown")
 	ErrICanWaitNoLonger = errors.New("ICanWaitNoLonger!")
 	ErrQueueFull        = errors.New("queue full")
+	ErrOutOfService     = errors.New("OutOfService")
+	ErrNotSeated        = errors.New("NotSeated")
+	ErrAlreadyPaused    = errors.New("AlreadyPaused")
+	ErrNotPaused        = errors.New("NotPaused")
+	ErrAlreadyAtTable   = errors.New("AlreadyAtTable")
 )
 
+// Release is a paused table given up after the maximum pause. Seated is the
+// client seated at it from the queue, if any.
+type Release struct {
+	ClientName string
+	TableID    int
+	At         time.Time
+	Seated     *models.Client
+}
+
 type Service struct {
-	cfg  *config.Config
-	repo Storage
+	cfg       *config.Config
+	repo      Storage
+	observers []Observer
+	muted     bool
 }
 
 type Storage interface {
-	AddClient(name string) error
+	AddClient(name string, timestamp time.Time) error
 	CheckFreeTables() bool
-	EnqueueClient(name string) error
-	DequeueClient() *models.Client
-	RemoveClient(name string)
+	EnqueueClient(name string, timestamp time.Time) error
+	DequeueClient(timestamp time.Time) *models.Client
+	RemoveClient(name string, timestamp time.Time)
 	FreedTableByClient(name string, timeSat time.Time) int
 	ClientExists(name string) bool
 	SetClientTable(name string, tableID int, timeSat time.Time) error
+	MoveClient(name string, tableID int, timestamp time.Time) error
 	KickAllClientsAndClearTables(kickTime time.Time)
-	ClearAllClients() []*models.Client
+	ClearAllClients(kickTime time.Time) []*models.Client
 	GetAllTables() map[int]*models.Table
+	FreeTable() int
+	DisableTable(tableID int, timestamp time.Time) (*models.Client, error)
+	EnableTable(tableID int, timestamp time.Time) bool
+	PauseClient(name string, timestamp time.Time) error
+	ResumeClient(name string, timestamp time.Time) error
+	AddExtra(extra *models.Extra) error
+	GetExtras() []*models.Extra
+	TakeEndedVisits() []*models.Visit
+	GetSessions() []*models.Session
+	GetWaits() []*models.Wait
+	GetVisits() []*models.Visit
+	OnChange(fn func(*models.Change))
+	Reset()
 }
 
 func New(cfg *config.Config, repo Storage) *Service {
-	return &Service{
+	s := &Service{
 		cfg:  cfg,
 		repo: repo,
 	}
+	repo.OnChange(s.notifyChange)
+	return s
 }
 
 func (s *Service) ClientArrive(timestamp time.Time, name string) error {
 	if !timestamp.After(s.cfg.OpeningTime) {
 		return ErrNotOpenYet
 	}
-	err := s.repo.AddClient(name)
+	err := s.repo.AddClient(name, timestamp)
 	if err != nil {
 		if errors.Is(err, storage.ErrClientExists) {
 			return ErrYouShallNotPass
@@ -63,12 +96,17 @@ func (s *Service) ClientSit(timestamp time.Time, name string, tableID int) error
 	if !exists {
 		return ErrClientUnknown
 	}
-	_ = s.repo.FreedTableByClient(name, timestamp)
-	err := s.repo.SetClientTable(name, tableID, timestamp)
+	err := s.repo.MoveClient(name, tableID, timestamp)
 	if err != nil {
 		if errors.Is(err, storage.ErrTableOccupied) {
 			return ErrPlaceIsBusy
 		}
+		if errors.Is(err, storage.ErrOutOfService) {
+			return ErrOutOfService
+		}
+		if errors.Is(err, storage.ErrAlreadyAtTable) {
+			return ErrAlreadyAtTable
+		}
 	}
 	return nil
 }
@@ -81,7 +119,7 @@ func (s *Service) ClientWait(timestamp time.Time, name string) error {
 	if !exists {
 		return nil
 	}
-	err := s.repo.EnqueueClient(name)
+	err := s.repo.EnqueueClient(name, timestamp)
 	if err != nil {
 		if errors.Is(err, queue.ErrQueueFull) {
 			return ErrQueueFull
@@ -96,8 +134,12 @@ func (s *Service) ClientLeave(timestamp time.Time, name string) (*models.Client,
 		return nil, 0, ErrClientUnknown
 	}
 	freeTable := s.repo.FreedTableByClient(name, timestamp)
-	s.repo.RemoveClient(name)
-	dequeued := s.repo.DequeueClient()
+	s.repo.RemoveClient(name, timestamp)
+	// A client without a table frees nothing, so the queue keeps waiting.
+	if freeTable == 0 {
+		return nil, 0, nil
+	}
+	dequeued := s.repo.DequeueClient(timestamp)
 	if dequeued == nil {
 		return nil, 0, nil
 	}
@@ -108,9 +150,117 @@ func (s *Service) ClientLeave(timestamp time.Time, name string) (*models.Client,
 	return dequeued, freeTable, nil
 }
 
+func (s *Service) ClientOrder(extra *models.Extra) error {
+	if !s.repo.ClientExists(extra.ClientName) {
+		return ErrClientUnknown
+	}
+	return s.repo.AddExtra(extra)
+}
+
+func (s *Service) ClientPause(timestamp time.Time, name string) error {
+	if !s.repo.ClientExists(name) {
+		return ErrClientUnknown
+	}
+	return pauseError(s.repo.PauseClient(name, timestamp))
+}
+
+func (s *Service) ClientResume(timestamp time.Time, name string) error {
+	if !s.repo.ClientExists(name) {
+		return ErrClientUnknown
+	}
+	return pauseError(s.repo.ResumeClient(name, timestamp))
+}
+
+func pauseError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotSeated):
+		return ErrNotSeated
+	case errors.Is(err, storage.ErrAlreadyPaused):
+		return ErrAlreadyPaused
+	case errors.Is(err, storage.ErrNotPaused):
+		return ErrNotPaused
+	}
+	return err
+}
+
+// ReleaseExpired frees every table paused for longer than the maximum pause
+// before now. A table is freed at the moment its pause ran out and the first
+// client in the queue is seated at it.
+func (s *Service) ReleaseExpired(now time.Time) []*Release {
+	if s.cfg.MaxPause <= 0 {
+		return nil
+	}
+	var releases []*Release
+	for _, t := range s.repo.GetAllTables() {
+		if t.Client == nil || t.PausedAt.IsZero() {
+			continue
+		}
+		if at := t.PausedAt.Add(s.cfg.MaxPause); at.Before(now) {
+			releases = append(releases, &Release{ClientName: t.Client.Name, TableID: t.Id, At: at})
+		}
+	}
+	slices.SortFunc(releases, func(a, b *Release) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return a.TableID - b.TableID
+	})
+	for _, r := range releases {
+		s.repo.FreedTableByClient(r.ClientName, r.At)
+		r.Seated = s.repo.DequeueClient(r.At)
+		if r.Seated != nil {
+			if err := s.repo.SetClientTable(r.Seated.Name, r.TableID, r.At); err != nil {
+				r.Seated = nil
+			}
+		}
+	}
+	return releases
+}
+
+// TableOutOfService takes a table out of rotation. A client sitting at it is
+// returned together with the table they were moved to, or 0 if there was no
+// free table and they were put in the queue. When the queue is full the
+// client leaves the club and ErrQueueFull is returned.
+func (s *Service) TableOutOfService(timestamp time.Time, tableID int) (*models.Client, int, error) {
+	client, err := s.repo.DisableTable(tableID, timestamp)
+	if err != nil {
+		return nil, 0, ErrOutOfService
+	}
+	if client == nil {
+		return nil, 0, nil
+	}
+	if free := s.repo.FreeTable(); free != 0 {
+		if err := s.repo.SetClientTable(client.Name, free, timestamp); err != nil {
+			return nil, 0, err
+		}
+		return client, free, nil
+	}
+	if err := s.repo.EnqueueClient(client.Name, timestamp); err != nil {
+		s.repo.RemoveClient(client.Name, timestamp)
+		return client, 0, ErrQueueFull
+	}
+	return client, 0, nil
+}
+
+// TableInService puts a table back in rotation and seats the first client
+// in the queue at it.
+func (s *Service) TableInService(timestamp time.Time, tableID int) (*models.Client, error) {
+	if !s.repo.EnableTable(tableID, timestamp) {
+		return nil, nil
+	}
+	dequeued := s.repo.DequeueClient(timestamp)
+	if dequeued == nil {
+		return nil, nil
+	}
+	if err := s.repo.SetClientTable(dequeued.Name, tableID, timestamp); err != nil {
+		return nil, err
+	}
+	return dequeued, nil
+}
+
 func (s *Service) KickClients(kickTime time.Time) []*models.Client {
 	s.repo.KickAllClientsAndClearTables(kickTime)
-	kicked := s.repo.ClearAllClients()
+	kicked := s.repo.ClearAllClients(kickTime)
 	return kicked
 }
 
@@ -119,16 +269,42 @@ func (s *Service) CalcProfits() []*models.Profit {
 
 	profits := make([]*models.Profit, 0, len(tables))
 	for _, v := range tables {
-		total := v.TotalTime.Hour()
-		if v.TotalTime.Minute() != 0 {
-			total += 1
-		}
 		p := &models.Profit{
 			Table: v,
-			Sum:   int(total) * s.cfg.HourlyRate,
+			Sum:   s.cfg.RateFor(v.Id).Times(models.BillableHours(v.TotalTime)),
 		}
 		profits = append(profits, p)
 	}
 
 	return profits
 }
+
+func (s *Service) Sessions() []*models.Session {
+	return s.repo.GetSessions()
+}
+
+func (s *Service) Waits() []*models.Wait {
+	return s.repo.GetWaits()
+}
+
+func (s *Service) Visits() []*models.Visit {
+	return s.repo.GetVisits()
+}
+
+func (s *Service) Extras() []*models.Extra {
+	return s.repo.GetExtras()
+}
+
+// EndedVisits returns the visits ended since the last call.
+func (s *Service) EndedVisits() []*models.Visit {
+	return s.repo.TakeEndedVisits()
+}
+
+// Replay starts the day over and runs fn to process the events again.
+// Observers are not called while fn runs.
+func (s *Service) Replay(fn func()) {
+	s.muted = true
+	defer func() { s.muted = false }()
+	s.repo.Reset()
+	fn()
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Korpenter/club/internal/config"
 	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/storage"
 	"github.com/Korpenter/club/internal/utils"
 )
 
@@ -14,10 +15,11 @@ type MockStorage struct {
 	tableIsFree    bool
 	errorToReturn  error
 	dequeuedClient *models.Client
+	noTable        bool
 	AllTables      map[int]*models.Table
 }
 
-func (m *MockStorage) AddClient(name string) error {
+func (m *MockStorage) AddClient(name string, timestamp time.Time) error {
 	return m.errorToReturn
 }
 
@@ -25,17 +27,20 @@ func (m *MockStorage) CheckFreeTables() bool {
 	return m.tableIsFree
 }
 
-func (m *MockStorage) EnqueueClient(name string) error {
+func (m *MockStorage) EnqueueClient(name string, timestamp time.Time) error {
 	return m.errorToReturn
 }
 
-func (m *MockStorage) DequeueClient() *models.Client {
+func (m *MockStorage) DequeueClient(timestamp time.Time) *models.Client {
 	return m.dequeuedClient
 }
 
-func (m *MockStorage) RemoveClient(name string) {}
+func (m *MockStorage) RemoveClient(name string, timestamp time.Time) {}
 
 func (m *MockStorage) FreedTableByClient(name string, timeSat time.Time) int {
+	if m.noTable {
+		return 0
+	}
 	return 1
 }
 
@@ -47,9 +52,13 @@ func (m *MockStorage) SetClientTable(name string, tableID int, timeSat time.Time
 	return m.errorToReturn
 }
 
+func (m *MockStorage) MoveClient(name string, tableID int, timestamp time.Time) error {
+	return m.errorToReturn
+}
+
 func (m *MockStorage) KickAllClientsAndClearTables(kickTime time.Time) {}
 
-func (m *MockStorage) ClearAllClients() []*models.Client {
+func (m *MockStorage) ClearAllClients(kickTime time.Time) []*models.Client {
 	return nil
 }
 
@@ -57,6 +66,60 @@ func (m *MockStorage) GetAllTables() map[int]*models.Table {
 	return m.AllTables
 }
 
+func (m *MockStorage) GetSessions() []*models.Session {
+	return nil
+}
+
+func (m *MockStorage) GetWaits() []*models.Wait {
+	return nil
+}
+
+func (m *MockStorage) GetVisits() []*models.Visit {
+	return nil
+}
+
+func (m *MockStorage) OnChange(fn func(*models.Change)) {}
+
+func (m *MockStorage) Reset() {}
+
+func (m *MockStorage) FreeTable() int {
+	if m.tableIsFree {
+		return 2
+	}
+	return 0
+}
+
+func (m *MockStorage) DisableTable(tableID int, timestamp time.Time) (*models.Client, error) {
+	if m.noTable {
+		return nil, m.errorToReturn
+	}
+	return &models.Client{Name: "client1"}, m.errorToReturn
+}
+
+func (m *MockStorage) PauseClient(name string, timestamp time.Time) error {
+	return m.errorToReturn
+}
+
+func (m *MockStorage) ResumeClient(name string, timestamp time.Time) error {
+	return m.errorToReturn
+}
+
+func (m *MockStorage) AddExtra(extra *models.Extra) error {
+	return m.errorToReturn
+}
+
+func (m *MockStorage) GetExtras() []*models.Extra {
+	return nil
+}
+
+func (m *MockStorage) TakeEndedVisits() []*models.Visit {
+	return nil
+}
+
+func (m *MockStorage) EnableTable(tableID int, timestamp time.Time) bool {
+	return true
+}
+
 func TestClientArrive(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -112,6 +175,22 @@ func TestClientSit(t *testing.T) {
 			mock:      &MockStorage{},
 			wantErr:   ErrClientUnknown,
 		},
+		{
+			name:      "Client sits at a busy table",
+			timestamp: time.Now(),
+			client:    "aohn",
+			tableID:   2,
+			mock:      &MockStorage{exists: true, errorToReturn: storage.ErrTableOccupied},
+			wantErr:   ErrPlaceIsBusy,
+		},
+		{
+			name:      "Client sits at their own table",
+			timestamp: time.Now(),
+			client:    "aohn",
+			tableID:   1,
+			mock:      &MockStorage{exists: true, errorToReturn: storage.ErrAlreadyAtTable},
+			wantErr:   ErrAlreadyAtTable,
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +251,13 @@ func TestClientLeave(t *testing.T) {
 			mock:      &MockStorage{exists: true, dequeuedClient: &models.Client{Name: "oppa"}},
 			wantErr:   nil,
 		},
+		{
+			name:      "Client without a table leaves while others wait",
+			timestamp: time.Now(),
+			client:    "bab",
+			mock:      &MockStorage{exists: true, noTable: true, dequeuedClient: &models.Client{Name: "oppa"}},
+			wantErr:   nil,
+		},
 		{
 			name:      "Unknown client tries to leave",
 			timestamp: time.Now(),
@@ -186,25 +272,64 @@ func TestClientLeave(t *testing.T) {
 			cfg := &config.Config{}
 			s := New(cfg, tt.mock)
 
-			_, _, err := s.ClientLeave(tt.timestamp, tt.client)
+			dequeued, _, err := s.ClientLeave(tt.timestamp, tt.client)
 			if err != tt.wantErr {
 				t.Errorf("Expected error: %v, got: %v", tt.wantErr, err)
 			}
+			if tt.mock.noTable && dequeued != nil {
+				t.Errorf("Expected nobody seated from the queue, got: %v", dequeued.Name)
+			}
 		})
 	}
 }
 
+func TestClientLeaveWithoutTable(t *testing.T) {
+	cfg := &config.Config{NumberOfTables: 1, QueueCapacity: 1}
+	cfg.OpeningTime, _ = utils.Parse("09:00")
+	cfg.ClosingTime, _ = utils.Parse("19:00")
+	repo := storage.NewInMemRepo(cfg)
+	s := New(cfg, repo)
+	at, _ := utils.Parse("10:00")
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if err := s.ClientArrive(at, name); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if err := s.ClientSit(at, "alice", 1); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := s.ClientWait(at, "bob"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	dequeued, table, err := s.ClientLeave(at.Add(time.Hour), "carol")
+	if err != nil || dequeued != nil || table != 0 {
+		t.Errorf("Expected nobody seated, got: %v at table %d, error %v", dequeued, table, err)
+	}
+	if queue := repo.GetQueue(); len(queue) != 1 || queue[0].Name != "bob" {
+		t.Errorf("Expected bob still waiting, got: %v", queue)
+	}
+	if c := repo.GetAllTables()[1].Client; c == nil || c.Name != "alice" {
+		t.Errorf("Expected alice still at table 1, got: %v", c)
+	}
+
+	dequeued, table, err = s.ClientLeave(at.Add(2*time.Hour), "alice")
+	if err != nil || dequeued == nil || dequeued.Name != "bob" || table != 1 {
+		t.Errorf("Expected bob seated at table 1, got: %v at table %d, error %v", dequeued, table, err)
+	}
+}
+
 func TestCalcProfits(t *testing.T) {
 	cfg := &config.Config{
-		HourlyRate: 10,
+		HourlyRate: 1000,
 	}
-	time1, _ := utils.Parse("5:45")
-	time2, _ := utils.Parse("01:15")
+	time1 := 5*time.Hour + 45*time.Minute
+	time2 := 26*time.Hour + 15*time.Minute
 	tests := []struct {
 		name      string
 		tables    map[int]*models.Table
 		mock      *MockStorage
-		wantTotal int
+		wantTotal models.Money
 	}{
 		{
 			name: "Calculate profits for multiple tables",
@@ -218,7 +343,7 @@ func TestCalcProfits(t *testing.T) {
 					2: {TotalTime: time2},
 				},
 			},
-			wantTotal: (6 + 2) * 10,
+			wantTotal: (6 + 27) * 1000,
 		},
 	}
 
@@ -227,7 +352,7 @@ func TestCalcProfits(t *testing.T) {
 			s := New(cfg, tt.mock)
 
 			profits := s.CalcProfits()
-			total := 0
+			var total models.Money
 			for _, p := range profits {
 				total += p.Sum
 			}
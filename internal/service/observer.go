@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/Korpenter/club/internal/models"
+)
+
+// Observer is called synchronously for every event the club logs and for
+// every table or queue change. Incoming events are the ones read from the
+// input, outgoing events are generated by the club.
+type Observer interface {
+	OnEvent(e *models.Event, incoming bool)
+	OnChange(c *models.Change)
+}
+
+func (s *Service) AddObserver(o Observer) {
+	s.observers = append(s.observers, o)
+}
+
+// NotifyEvent passes an event to the observers in the order they were added.
+func (s *Service) NotifyEvent(e *models.Event, incoming bool) {
+	if s.muted {
+		return
+	}
+	for _, o := range s.observers {
+		o.OnEvent(e, incoming)
+	}
+}
+
+func (s *Service) notifyChange(c *models.Change) {
+	if s.muted {
+		return
+	}
+	for _, o := range s.observers {
+		o.OnChange(c)
+	}
+}
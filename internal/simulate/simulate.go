@@ -0,0 +1,231 @@
+package simulate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Korpenter/club/internal/config"
+	"github.com/Korpenter/club/internal/handler"
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/service"
+	"github.com/Korpenter/club/internal/storage"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const (
+	RemapMissing = "missing"
+	RemapFree    = "free"
+)
+
+// Variant describes the layout to replay the log against. Zero values keep
+// the setting of the actual day.
+type Variant struct {
+	Tables        int
+	QueueCapacity int
+	HourlyRate    models.Money
+	Remap         string
+}
+
+type Result struct {
+	Tables        int
+	QueueCapacity int
+	HourlyRate    models.Money
+	Revenue       models.Money
+	Utilization   float64
+	Sessions      int
+	GaveUp        int
+	LeftWaiting   int
+	Unserved      int
+	Errors        int
+}
+
+func (r *Result) Lost() int {
+	return r.GaveUp + r.LeftWaiting + r.Unserved
+}
+
+type Comparison struct {
+	Actual    *Result
+	Simulated *Result
+}
+
+// Run replays the event log data once with its own configuration and once
+// with the variant applied. Seat requests for tables missing from the
+// variant layout go to the lowest-numbered free table, and a client asking
+// to wait while a table is free is seated there instead. With RemapFree
+// busy tables are remapped the same way. Timestamps are read in the format
+// tf.
+func Run(data []byte, configPath string, v Variant, tf utils.TimeFormat) (*Comparison, error) {
+	switch v.Remap {
+	case "", RemapMissing, RemapFree:
+	default:
+		return nil, fmt.Errorf("unknown remap mode %q", v.Remap)
+	}
+
+	actual, err := replay(data, configPath, nil, tf)
+	if err != nil {
+		return nil, err
+	}
+	simulated, err := replay(data, configPath, &v, tf)
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Actual: actual, Simulated: simulated}, nil
+}
+
+func replay(data []byte, configPath string, v *Variant, tf utils.TimeFormat) (*Result, error) {
+	cfg, err := config.Load(bufio.NewReader(bytes.NewReader(data)), configPath, tf)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Output.Stats = true
+	logged := cfg.NumberOfTables
+
+	if v != nil {
+		if v.Tables > 0 {
+			cfg.NumberOfTables = v.Tables
+			cfg.QueueCapacity = v.Tables
+		}
+		if v.QueueCapacity > 0 {
+			cfg.QueueCapacity = v.QueueCapacity
+		}
+		if v.HourlyRate > 0 {
+			cfg.HourlyRate = v.HourlyRate
+		}
+	}
+
+	repo := storage.NewInMemRepo(cfg)
+	var svc handler.Service = service.New(cfg, repo)
+	if v != nil {
+		svc = &remapService{
+			Service: service.New(cfg, repo),
+			repo:    repo,
+			free:    v.Remap == RemapFree,
+		}
+	}
+
+	// Sit events are validated against the layout they were logged with;
+	// remapService moves them onto the simulated tables.
+	hcfg := *cfg
+	hcfg.NumberOfTables = logged
+	h := handler.NewFileHandler(cfg.FileScanner, svc, &hcfg)
+	if err := h.ProcessEvents(); err != nil {
+		return nil, err
+	}
+	day := h.Report()
+
+	res := &Result{
+		Tables:        cfg.NumberOfTables,
+		QueueCapacity: cfg.QueueCapacity,
+		HourlyRate:    cfg.HourlyRate,
+		Sessions:      len(day.Sessions),
+		GaveUp:        day.Stats.Queue.GaveUp,
+		LeftWaiting:   day.Stats.Queue.LeftWhileWaiting,
+		Unserved:      day.Stats.Queue.UnservedAtClose,
+	}
+	var occupied time.Duration
+	for _, p := range day.Profits {
+		res.Revenue += p.Sum
+		occupied += p.Table.TotalTime
+	}
+	if open := cfg.ClosingTime.Sub(cfg.OpeningTime); open > 0 {
+		res.Utilization = float64(occupied) / float64(open*time.Duration(cfg.NumberOfTables)) * 100
+	}
+	for _, n := range day.Stats.Errors {
+		res.Errors += n
+	}
+	return res, nil
+}
+
+type remapService struct {
+	*service.Service
+	repo *storage.InMemRepo
+	free bool
+}
+
+func (s *remapService) ClientSit(timestamp time.Time, name string, tableID int) error {
+	tables := s.repo.GetAllTables()
+	table, ok := tables[tableID]
+	if !ok || (s.free && (table.Client != nil || table.OutOfService)) {
+		free := s.freeTable()
+		if free == 0 {
+			return service.ErrPlaceIsBusy
+		}
+		tableID = free
+	}
+	return s.Service.ClientSit(timestamp, name, tableID)
+}
+
+// ClientWait seats a client who asks to wait while a table is free. The
+// client only waited because the logged layout was full, so turning them
+// away would count extra tables against the variant. A client the variant
+// already seated keeps the table instead of joining the queue.
+func (s *remapService) ClientWait(timestamp time.Time, name string) error {
+	if s.seated(name) {
+		return nil
+	}
+	err := s.Service.ClientWait(timestamp, name)
+	if errors.Is(err, service.ErrICanWaitNoLonger) {
+		if free := s.freeTable(); free != 0 {
+			return s.Service.ClientSit(timestamp, name, free)
+		}
+	}
+	return err
+}
+
+func (s *remapService) freeTable() int {
+	return s.repo.FreeTable()
+}
+
+func (s *remapService) seated(name string) bool {
+	for _, t := range s.repo.GetAllTables() {
+		if t.Client != nil && t.Client.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Maintenance of a table that does not exist in the simulated layout is
+// ignored.
+func (s *remapService) TableOutOfService(timestamp time.Time, tableID int) (*models.Client, int, error) {
+	if _, ok := s.repo.GetAllTables()[tableID]; !ok {
+		return nil, 0, nil
+	}
+	return s.Service.TableOutOfService(timestamp, tableID)
+}
+
+func (s *remapService) TableInService(timestamp time.Time, tableID int) (*models.Client, error) {
+	if _, ok := s.repo.GetAllTables()[tableID]; !ok {
+		return nil, nil
+	}
+	return s.Service.TableInService(timestamp, tableID)
+}
+
+func (c *Comparison) WriteText(w io.Writer) {
+	a, s := c.Actual, c.Simulated
+	rows := []struct {
+		name      string
+		actual    string
+		simulated string
+	}{
+		{"tables", fmt.Sprint(a.Tables), fmt.Sprint(s.Tables)},
+		{"queue capacity", fmt.Sprint(a.QueueCapacity), fmt.Sprint(s.QueueCapacity)},
+		{"hourly rate", fmt.Sprint(a.HourlyRate), fmt.Sprint(s.HourlyRate)},
+		{"revenue", fmt.Sprint(a.Revenue), fmt.Sprint(s.Revenue)},
+		{"utilization", fmt.Sprintf("%.1f%%", a.Utilization), fmt.Sprintf("%.1f%%", s.Utilization)},
+		{"sessions", fmt.Sprint(a.Sessions), fmt.Sprint(s.Sessions)},
+		{"clients lost", fmt.Sprint(a.Lost()), fmt.Sprint(s.Lost())},
+		{"  queue full", fmt.Sprint(a.GaveUp), fmt.Sprint(s.GaveUp)},
+		{"  left waiting", fmt.Sprint(a.LeftWaiting), fmt.Sprint(s.LeftWaiting)},
+		{"  unserved at close", fmt.Sprint(a.Unserved), fmt.Sprint(s.Unserved)},
+		{"errors", fmt.Sprint(a.Errors), fmt.Sprint(s.Errors)},
+	}
+	fmt.Fprintf(w, "%-20s %10s %10s\n", "", "actual", "simulated")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%-20s %10s %10s\n", r.name, r.actual, r.simulated)
+	}
+}
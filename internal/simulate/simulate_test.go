@@ -0,0 +1,93 @@
+package simulate
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Korpenter/club/internal/utils"
+)
+
+const log = `2
+09:00 19:00
+10
+09:10 1 anna
+09:11 1 boris
+09:12 1 clara
+09:13 2 anna 1
+09:14 2 boris 2
+09:15 3 clara
+09:20 1 dima
+09:21 3 dima
+09:22 1 egor
+09:23 3 egor
+12:00 4 anna
+`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		variant   Variant
+		simulated Result
+	}{
+		{
+			name:    "same layout",
+			variant: Variant{},
+			simulated: Result{Tables: 2, QueueCapacity: 2, HourlyRate: 1000, Revenue: 20000,
+				Sessions: 3, GaveUp: 1, Unserved: 1, Errors: 0},
+		},
+		{
+			name:    "fewer tables remaps missing table",
+			variant: Variant{Tables: 1, QueueCapacity: 1},
+			simulated: Result{Tables: 1, QueueCapacity: 1, HourlyRate: 1000, Revenue: 10000,
+				Sessions: 2, GaveUp: 2, Unserved: 0, Errors: 1},
+		},
+		{
+			name:    "more tables seat waiting clients",
+			variant: Variant{Tables: 4, Remap: RemapFree, HourlyRate: 2000},
+			simulated: Result{Tables: 4, QueueCapacity: 4, HourlyRate: 2000, Revenue: 80000,
+				Sessions: 5, GaveUp: 0, Unserved: 0, Errors: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmp, err := Run([]byte(log), "", tt.variant, utils.TimeFormat{})
+			if err != nil {
+				t.Fatalf("didn't expect error but got %v", err)
+			}
+			if cmp.Actual.Revenue != 20000 || cmp.Actual.Lost() != 2 {
+				t.Errorf("Unexpected actual result: %+v", cmp.Actual)
+			}
+			got := *cmp.Simulated
+			got.Utilization = 0
+			if got != tt.simulated {
+				t.Errorf("Expected simulated %+v, got %+v", tt.simulated, got)
+			}
+		})
+	}
+}
+
+func TestRunMoreTables(t *testing.T) {
+	data, err := os.ReadFile("../../data/valid.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, remap := range []string{RemapMissing, RemapFree} {
+		var prev *Result
+		for tables := 3; tables <= 8; tables++ {
+			cmp, err := Run(data, "", Variant{Tables: tables, Remap: remap}, utils.TimeFormat{})
+			if err != nil {
+				t.Fatalf("didn't expect error but got %v", err)
+			}
+			if prev == nil {
+				prev = cmp.Actual
+			}
+			got := cmp.Simulated
+			if got.Revenue < prev.Revenue || got.Lost() > prev.Lost() {
+				t.Errorf("%s remap, %d tables: revenue %s and %d lost after %s and %d with %d tables",
+					remap, tables, got.Revenue, got.Lost(), prev.Revenue, prev.Lost(), prev.Tables)
+			}
+			prev = got
+		}
+	}
+}
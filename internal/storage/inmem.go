@@ -15,41 +15,76 @@ var (
 	ErrClientNotInQueue = errors.New("client not in the queue")
 	ErrClientExists     = errors.New("client already exists")
 	ErrClientUnknown    = errors.New("unknown client")
+	ErrOutOfService     = errors.New("table out of service")
+	ErrNotSeated        = errors.New("client is not seated")
+	ErrAlreadyPaused    = errors.New("session already paused")
+	ErrNotPaused        = errors.New("session is not paused")
+	ErrAlreadyAtTable   = errors.New("client already sits at the table")
 )
 
 type InMemRepo struct {
+	cfg     *config.Config
 	tables  map[int]*models.Table
 	queue   Queue
 	clients map[string]*models.Client
 	events  []*models.Event
+	waiting map[string]time.Time
+
+	sessions []*models.Session
+	waits    []*models.Wait
+	visits   map[string]*models.Visit
+	history  []*models.Visit
+	extras   []*models.Extra
+	ended    []*models.Visit
+
+	onChange func(*models.Change)
 }
 
 type Queue interface {
 	Enqueue(*models.Client) error
 	Dequeue() *models.Client
 	Remove(client *models.Client)
+	Clients() []*models.Client
 	Clear()
 }
 
 func NewInMemRepo(cfg *config.Config) *InMemRepo {
-	queue := queue.NewQueue(cfg.NumberOfTables)
-	tables := make(map[int]*models.Table, cfg.NumberOfTables)
-	for i := 1; i <= cfg.NumberOfTables; i++ {
-		tables[i] = &models.Table{Id: i}
-	}
-	return &InMemRepo{
-		tables:  tables,
-		queue:   queue,
-		clients: make(map[string]*models.Client),
-		events:  make([]*models.Event, 0),
+	r := &InMemRepo{cfg: cfg}
+	r.Reset()
+	return r
+}
+
+// Reset drops all clients, tables and history and starts the day over.
+func (r *InMemRepo) Reset() {
+	r.tables = make(map[int]*models.Table, r.cfg.NumberOfTables)
+	for i := 1; i <= r.cfg.NumberOfTables; i++ {
+		r.tables[i] = &models.Table{Id: i}
 	}
+	r.queue = queue.NewQueue(r.cfg.QueueCapacity)
+	r.clients = make(map[string]*models.Client)
+	r.events = make([]*models.Event, 0)
+	r.waiting = make(map[string]time.Time)
+	r.sessions = nil
+	r.waits = nil
+	r.visits = make(map[string]*models.Visit)
+	r.history = nil
+	r.extras = nil
+	r.ended = nil
 }
 
-func (r *InMemRepo) AddClient(name string) error {
+// OnChange sets the function called for every table and queue change.
+func (r *InMemRepo) OnChange(fn func(*models.Change)) {
+	r.onChange = fn
+}
+
+func (r *InMemRepo) AddClient(name string, timestamp time.Time) error {
 	if _, exists := r.clients[name]; exists {
 		return ErrClientExists
 	}
 	r.clients[name] = &models.Client{Name: name}
+	visit := &models.Visit{ClientName: name, Arrived: timestamp}
+	r.visits[name] = visit
+	r.history = append(r.history, visit)
 	return nil
 }
 
@@ -59,64 +94,184 @@ func (r *InMemRepo) ClientExists(name string) bool {
 }
 
 func (r *InMemRepo) CheckFreeTables() bool {
-	for _, v := range r.tables {
-		if v.Client == nil {
-			return true
+	return r.FreeTable() != 0
+}
+
+// FreeTable returns the free table in service with the lowest number, or 0
+// if there is none.
+func (r *InMemRepo) FreeTable() int {
+	for i := 1; i <= len(r.tables); i++ {
+		if t, ok := r.tables[i]; ok && t.Client == nil && !t.OutOfService {
+			return i
 		}
 	}
-	return false
+	return 0
 }
 
-func (r *InMemRepo) EnqueueClient(name string) error {
-	return r.queue.Enqueue(r.clients[name])
+func (r *InMemRepo) EnqueueClient(name string, timestamp time.Time) error {
+	if err := r.queue.Enqueue(r.clients[name]); err != nil {
+		return err
+	}
+	r.waiting[name] = timestamp
+	r.changed(models.QueueJoined, timestamp, name, 0)
+	return nil
 }
 
-func (r *InMemRepo) DequeueClient() *models.Client {
-	return r.queue.Dequeue()
+func (r *InMemRepo) DequeueClient(timestamp time.Time) *models.Client {
+	client := r.queue.Dequeue()
+	if client != nil {
+		r.endWait(client.Name, timestamp, models.WaitSeated)
+	}
+	return client
 }
 
 func (r *InMemRepo) SetClientTable(name string, tableID int, timeSat time.Time) error {
+	if r.tables[tableID].OutOfService {
+		return ErrOutOfService
+	}
 	if r.tables[tableID].Client != nil {
 		return ErrTableOccupied
 	}
 	r.tables[tableID].Client = r.clients[name]
 	r.tables[tableID].ClientSat = timeSat
+	r.changed(models.TableOccupied, timeSat, name, tableID)
 	return nil
 }
 
+// MoveClient seats a client at a table. A client already seated elsewhere
+// ends the session there and starts a new one, unless the table cannot be
+// taken: then they keep their seat and session.
+func (r *InMemRepo) MoveClient(name string, tableID int, timestamp time.Time) error {
+	table := r.tables[tableID]
+	if table.OutOfService {
+		return ErrOutOfService
+	}
+	if table.Client != nil {
+		if table.Client.Name == name {
+			return ErrAlreadyAtTable
+		}
+		return ErrTableOccupied
+	}
+	if old := r.tableOf(name); old != nil {
+		r.endSession(old, timestamp)
+	}
+	return r.SetClientTable(name, tableID, timestamp)
+}
+
 func (r *InMemRepo) FreedTableByClient(name string, timeSat time.Time) int {
 	for i, v := range r.tables {
 		if v.Client != nil && v.Client.Name == name {
-			timeSpent := timeSat.Sub(v.ClientSat)
-			r.tables[i].TotalTime = r.tables[i].TotalTime.Add(timeSpent)
-			r.tables[i].Client = nil
-			r.tables[i].ClientSat = time.Time{}
+			r.endSession(v, timeSat)
 			return i
 		}
 	}
 	return 0
 }
 
-func (r *InMemRepo) RemoveClient(name string) {
+func (r *InMemRepo) PauseClient(name string, timestamp time.Time) error {
+	table := r.tableOf(name)
+	if table == nil {
+		return ErrNotSeated
+	}
+	if !table.PausedAt.IsZero() {
+		return ErrAlreadyPaused
+	}
+	table.PausedAt = timestamp
+	r.changed(models.SessionPaused, timestamp, name, table.Id)
+	return nil
+}
+
+func (r *InMemRepo) ResumeClient(name string, timestamp time.Time) error {
+	table := r.tableOf(name)
+	if table == nil {
+		return ErrNotSeated
+	}
+	if table.PausedAt.IsZero() {
+		return ErrNotPaused
+	}
+	table.Pauses = append(table.Pauses, &models.Pause{Start: table.PausedAt, End: timestamp})
+	table.PausedAt = time.Time{}
+	r.changed(models.SessionResumed, timestamp, name, table.Id)
+	return nil
+}
+
+func (r *InMemRepo) tableOf(name string) *models.Table {
+	for _, v := range r.tables {
+		if v.Client != nil && v.Client.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// AddExtra puts an item on the tab of the client's current visit.
+func (r *InMemRepo) AddExtra(extra *models.Extra) error {
+	visit, ok := r.visits[extra.ClientName]
+	if !ok {
+		return ErrClientUnknown
+	}
+	if table := r.tableOf(extra.ClientName); table != nil {
+		extra.TableID = table.Id
+	}
+	visit.Extras = append(visit.Extras, extra)
+	r.extras = append(r.extras, extra)
+	return nil
+}
+
+func (r *InMemRepo) RemoveClient(name string, timestamp time.Time) {
 	r.queue.Remove(r.clients[name])
+	r.endWait(name, timestamp, models.WaitLeft)
+	r.endVisit(name, timestamp, false)
 	delete(r.clients, name)
 }
 
+// DisableTable takes a table out of service. The session of a client
+// sitting at it is ended and the client is returned.
+func (r *InMemRepo) DisableTable(tableID int, timestamp time.Time) (*models.Client, error) {
+	table := r.tables[tableID]
+	if table.OutOfService {
+		return nil, ErrOutOfService
+	}
+	client := table.Client
+	if client != nil {
+		r.endSession(table, timestamp)
+	}
+	table.OutOfService = true
+	table.OutSince = timestamp
+	r.changed(models.TableDisabled, timestamp, "", tableID)
+	return client, nil
+}
+
+func (r *InMemRepo) EnableTable(tableID int, timestamp time.Time) bool {
+	table := r.tables[tableID]
+	if !table.OutOfService {
+		return false
+	}
+	table.OutOfService = false
+	table.Downtime += timestamp.Sub(table.OutSince)
+	table.OutSince = time.Time{}
+	r.changed(models.TableEnabled, timestamp, "", tableID)
+	return true
+}
+
 func (r *InMemRepo) KickAllClientsAndClearTables(kickTime time.Time) {
-	for i, v := range r.tables {
+	for _, v := range r.tables {
 		if v.Client != nil {
-			timeSpent := kickTime.Sub(v.ClientSat)
-			r.tables[i].TotalTime = r.tables[i].TotalTime.Add(timeSpent)
-			r.tables[i].Client = nil
-			r.tables[i].ClientSat = time.Time{}
+			r.endSession(v, kickTime)
+		}
+		if v.OutOfService && kickTime.After(v.OutSince) {
+			v.Downtime += kickTime.Sub(v.OutSince)
+			v.OutSince = kickTime
 		}
 	}
 }
 
-func (r *InMemRepo) ClearAllClients() []*models.Client {
+func (r *InMemRepo) ClearAllClients(kickTime time.Time) []*models.Client {
 	var clients []*models.Client
 	for _, v := range r.clients {
 		clients = append(clients, v)
+		r.endWait(v.Name, kickTime, models.WaitClosed)
+		r.endVisit(v.Name, kickTime, true)
 		delete(r.clients, v.Name)
 	}
 	r.queue.Clear()
@@ -126,3 +281,97 @@ func (r *InMemRepo) ClearAllClients() []*models.Client {
 func (r *InMemRepo) GetAllTables() map[int]*models.Table {
 	return r.tables
 }
+
+func (r *InMemRepo) GetQueue() []*models.Client {
+	return r.queue.Clients()
+}
+
+func (r *InMemRepo) GetSessions() []*models.Session {
+	return r.sessions
+}
+
+func (r *InMemRepo) GetWaits() []*models.Wait {
+	return r.waits
+}
+
+func (r *InMemRepo) GetVisits() []*models.Visit {
+	return r.history
+}
+
+func (r *InMemRepo) GetExtras() []*models.Extra {
+	return r.extras
+}
+
+// TakeEndedVisits returns the visits ended since the last call.
+func (r *InMemRepo) TakeEndedVisits() []*models.Visit {
+	ended := r.ended
+	r.ended = nil
+	return ended
+}
+
+func (r *InMemRepo) endSession(table *models.Table, end time.Time) {
+	if !table.PausedAt.IsZero() {
+		table.Pauses = append(table.Pauses, &models.Pause{Start: table.PausedAt, End: end})
+	}
+	session := &models.Session{
+		TableID:    table.Id,
+		ClientName: table.Client.Name,
+		Start:      table.ClientSat,
+		End:        end,
+		Pauses:     table.Pauses,
+	}
+	r.sessions = append(r.sessions, session)
+	if visit, ok := r.visits[session.ClientName]; ok {
+		visit.Sessions = append(visit.Sessions, session)
+	}
+	billed := models.BillableHours(table.TotalTime)
+	table.TotalTime += session.Duration()
+	session.Hours = models.BillableHours(table.TotalTime) - billed
+	table.Client = nil
+	table.ClientSat = time.Time{}
+	table.PausedAt = time.Time{}
+	table.Pauses = nil
+	r.changed(models.TableFreed, end, session.ClientName, table.Id)
+}
+
+func (r *InMemRepo) endWait(name string, end time.Time, outcome int) {
+	start, ok := r.waiting[name]
+	if !ok {
+		return
+	}
+	delete(r.waiting, name)
+	wait := &models.Wait{
+		ClientName: name,
+		Start:      start,
+		End:        end,
+		Outcome:    outcome,
+	}
+	r.waits = append(r.waits, wait)
+	if visit, ok := r.visits[name]; ok {
+		visit.Waits = append(visit.Waits, wait)
+	}
+	r.changed(models.QueueLeft, end, name, 0)
+}
+
+func (r *InMemRepo) endVisit(name string, end time.Time, forced bool) {
+	visit, ok := r.visits[name]
+	if !ok {
+		return
+	}
+	delete(r.visits, name)
+	visit.Left = end
+	visit.Forced = forced
+	r.ended = append(r.ended, visit)
+}
+
+func (r *InMemRepo) changed(kind int, timestamp time.Time, name string, tableID int) {
+	if r.onChange == nil {
+		return
+	}
+	r.onChange(&models.Change{
+		Kind:       kind,
+		Timestamp:  timestamp,
+		ClientName: name,
+		TableID:    tableID,
+	})
+}
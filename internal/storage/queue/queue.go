@@ -84,6 +84,15 @@ func (q *Queue) Remove(client *models.Client) {
 	}
 }
 
+// Clients returns the waiting clients from the head of the queue.
+func (q *Queue) Clients() []*models.Client {
+	clients := make([]*models.Client, 0, len(q.set))
+	for n := q.head; n != nil; n = n.next {
+		clients = append(clients, n.value)
+	}
+	return clients
+}
+
 func (q *Queue) Clear() {
 	q.head = nil
 	q.tail = nil
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Korpenter/club/internal/models"
+	"github.com/Korpenter/club/internal/utils"
+)
+
+// Snapshot is a copy of the club state that later events do not change.
+// Tables are ordered by number, clients by name.
+type Snapshot struct {
+	Tables  []*models.Table
+	Queue   []string
+	Clients []string
+	Extras  []*models.Extra
+}
+
+func (r *InMemRepo) Snapshot() *Snapshot {
+	s := &Snapshot{
+		Tables: make([]*models.Table, 0, len(r.tables)),
+		Extras: slices.Clone(r.extras),
+	}
+	for _, t := range r.tables {
+		table := *t
+		if t.Client != nil {
+			client := *t.Client
+			table.Client = &client
+		}
+		table.Pauses = slices.Clone(t.Pauses)
+		s.Tables = append(s.Tables, &table)
+	}
+	slices.SortFunc(s.Tables, func(a, b *models.Table) int {
+		return a.Id - b.Id
+	})
+	for _, c := range r.queue.Clients() {
+		s.Queue = append(s.Queue, c.Name)
+	}
+	for name := range r.clients {
+		s.Clients = append(s.Clients, name)
+	}
+	slices.Sort(s.Clients)
+	return s
+}
+
+// Write writes the tables, the queue and the clients of the snapshot, one
+// table per line, with times in the format tf.
+func (s *Snapshot) Write(w io.Writer, tf utils.TimeFormat) {
+	fmt.Fprintln(w, "tables:")
+	for _, t := range s.Tables {
+		state := "free"
+		switch {
+		case t.OutOfService:
+			state = "out of service since " + tf.Format(t.OutSince)
+		case t.Client != nil:
+			state = t.Client.Name + " since " + tf.Format(t.ClientSat)
+			if !t.PausedAt.IsZero() {
+				state += ", paused since " + tf.Format(t.PausedAt)
+			}
+		}
+		fmt.Fprintf(w, "  %d %s\n", t.Id, state)
+	}
+	fmt.Fprintln(w, "queue:", list(s.Queue))
+	fmt.Fprintln(w, "clients:", list(s.Clients))
+}
+
+func list(names []string) string {
+	if len(names) == 0 {
+		return "empty"
+	}
+	return strings.Join(names, ", ")
+}
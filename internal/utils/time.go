@@ -1,12 +1,28 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
-const timeLayout = "15:04"
+const (
+	timeLayout    = "15:04"
+	secondsLayout = "15:04:05"
+)
 
-func Parse(timestamp string) (time.Time, error) {
+// TimeFormat reads and writes timestamps. The zero value is the legacy
+// HH:MM format. Extended accepts HH:MM:SS, or RFC 3339 with a date and a
+// time zone; times are then written with seconds, and with the date when
+// they have one.
+type TimeFormat struct {
+	Extended bool
+}
+
+func (f TimeFormat) Parse(timestamp string) (time.Time, error) {
+	if f.Extended {
+		return parseExtended(timestamp)
+	}
 	parseed, err := time.Parse(timeLayout, timestamp)
 	if err != nil {
 		return time.Time{}, err
@@ -14,7 +30,51 @@ func Parse(timestamp string) (time.Time, error) {
 	return parseed, nil
 }
 
-func Format(timestamp time.Time) string {
+func parseExtended(timestamp string) (time.Time, error) {
+	if t, err := time.Parse(secondsLayout, timestamp); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timestamp " + timestamp)
+	}
+	return t, nil
+}
+
+func (f TimeFormat) Format(timestamp time.Time) string {
+	if f.Extended {
+		if HasDate(timestamp) {
+			return timestamp.Format(time.RFC3339)
+		}
+		return timestamp.Format(secondsLayout)
+	}
 	format := timestamp.Format(timeLayout)
 	return format
 }
+
+func (f TimeFormat) FormatDuration(d time.Duration) string {
+	if f.Extended {
+		return fmt.Sprintf("%02d:%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second))
+	}
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// Parse reads a legacy HH:MM timestamp.
+func Parse(timestamp string) (time.Time, error) {
+	return TimeFormat{}.Parse(timestamp)
+}
+
+// Format writes a legacy HH:MM timestamp.
+func Format(timestamp time.Time) string {
+	return TimeFormat{}.Format(timestamp)
+}
+
+func FormatDuration(d time.Duration) string {
+	return TimeFormat{}.FormatDuration(d)
+}
+
+// HasDate reports whether a time was parsed with a date. Times of day
+// parsed without one fall on year 0.
+func HasDate(t time.Time) bool {
+	return t.Year() > 1
+}
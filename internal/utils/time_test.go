@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtended(t *testing.T) {
+	t.Parallel()
+	f := TimeFormat{Extended: true}
+
+	tests := []struct {
+		input   string
+		want    string
+		hasDate bool
+	}{
+		{input: "09:10:05", want: "09:10:05"},
+		{input: "2026-10-19T09:10:05+03:00", want: "2026-10-19T09:10:05+03:00", hasDate: true},
+		{input: "2026-10-19T06:10:05Z", want: "2026-10-19T06:10:05Z", hasDate: true},
+	}
+	for _, tt := range tests {
+		ts, err := f.Parse(tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if got := f.Format(ts); got != tt.want || HasDate(ts) != tt.hasDate {
+			t.Errorf("%s: expected %s (date %v), got %s (date %v)", tt.input, tt.want, tt.hasDate, got, HasDate(ts))
+		}
+	}
+	for _, bad := range []string{"09:10", "2026-10-19T09:10:05"} {
+		if _, err := f.Parse(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	if got := f.FormatDuration(26*time.Hour + 5*time.Second); got != "26:00:05" {
+		t.Errorf("unexpected duration %s", got)
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	t.Parallel()
+	ts, err := Parse("09:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Format(ts) != "09:10" || HasDate(ts) {
+		t.Errorf("unexpected legacy time %s", Format(ts))
+	}
+	if _, err := Parse("09:10:05"); err == nil {
+		t.Error("expected seconds to be rejected in legacy mode")
+	}
+	if got := FormatDuration(90 * time.Minute); got != "01:30" {
+		t.Errorf("unexpected duration %s", got)
+	}
+}